@@ -0,0 +1,310 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// CheckpointFileName is the file in the export directory recording the
+// tables completed so far.
+const CheckpointFileName = ".db-puke-checkpoint.json"
+
+// exportCheckpoint records the tables completed by the running export.
+var exportCheckpoint *Checkpoint
+
+// CheckpointSource identifies the database and the output settings of an
+// export. -resume only continues an export with the same source.
+type CheckpointSource struct {
+	DBType         string `json:"type"`
+	Host           string `json:"host,omitempty"`
+	Port           int    `json:"port,omitempty"`
+	Database       string `json:"database"`
+	Schema         string `json:"schema,omitempty"`
+	SchemaLayout   string `json:"schema_layout,omitempty"`
+	User           string `json:"user,omitempty"`
+	Format         string `json:"format"`
+	Encoding       string `json:"encoding"`
+	EncodingError  string `json:"encoding_error"`
+	Delimiter      string `json:"delimiter"`
+	Quote          string `json:"quote"`
+	CRLF           bool   `json:"crlf"`
+	NoHeader       bool   `json:"no_header"`
+	ChunkParts     bool   `json:"chunk_parts,omitempty"`
+	BatchSize      int    `json:"batch_size,omitempty"`
+	IdentityInsert bool   `json:"identity_insert,omitempty"`
+	NullRepresent  string `json:"null"`
+	BinaryEncoding string `json:"binary_encoding,omitempty"`
+	Unsupported    string `json:"unsupported,omitempty"`
+	Filters        string `json:"filters,omitempty"`
+	Queries        string `json:"queries,omitempty"`
+}
+
+func currentCheckpointSource() CheckpointSource {
+	// the INSERT statements are only written in the sql format.
+	batchSize, identityInsert := 0, false
+	if commandOption.Format == OutputFormatSQL {
+		batchSize, identityInsert = commandOption.SQLBatchSize, commandOption.IdentityInsert
+	}
+
+	return CheckpointSource{
+		DBType:         commandOption.DBType,
+		Host:           commandOption.Host,
+		Port:           commandOption.Port,
+		Database:       commandOption.Database,
+		Schema:         commandOption.Schema,
+		SchemaLayout:   schemaLayoutSource(),
+		User:           commandOption.User,
+		Format:         commandOption.Format,
+		Encoding:       commandOption.Encoding,
+		EncodingError:  commandOption.EncodingError,
+		Delimiter:      string(commandOption.Delimiter),
+		Quote:          commandOption.Quote,
+		CRLF:           commandOption.CRLF,
+		NoHeader:       commandOption.NoHeader,
+		ChunkParts:     commandOption.ChunkParts,
+		BatchSize:      batchSize,
+		IdentityInsert: identityInsert,
+		NullRepresent:  commandOption.NullRepresent,
+		BinaryEncoding: commandOption.BinaryEncoding,
+		Unsupported:    commandOption.UnsupportedPolicy,
+		Filters:        tableFiltersSource(),
+		Queries:        queriesSource(),
+	}
+}
+
+// tableFiltersSource describes the filters of the tables and -limit, which
+// change the records exported.
+func tableFiltersSource() string {
+	if len(commandOption.TableFilters) == 0 && commandOption.Limit == 0 {
+		return ""
+	}
+	// the keys of the map are marshaled in order.
+	data, _ := json.Marshal(struct {
+		Tables map[string]*TableFilter `json:"tables,omitempty"`
+		Limit  int64                   `json:"limit,omitempty"`
+	}{commandOption.TableFilters, commandOption.Limit})
+	return string(data)
+}
+
+// schemaLayoutSource returns -schema-layout, which only places the files
+// of multiple schemas.
+func schemaLayoutSource() string {
+	if !multipleSchemas() {
+		return ""
+	}
+	return commandOption.SchemaLayout
+}
+
+// queriesSource describes the queries of query mode with the values of
+// their parameters, which change the records exported.
+func queriesSource() string {
+	if !queryMode() {
+		return ""
+	}
+	data, _ := json.Marshal(struct {
+		Queries []NamedQuery      `json:"queries"`
+		Params  map[string]string `json:"params,omitempty"`
+	}{commandOption.Queries, commandOption.Params})
+	return string(data)
+}
+
+func (s CheckpointSource) fields() [][2]string {
+	return [][2]string{
+		{"type", s.DBType},
+		{"host", s.Host},
+		{"port", strconv.Itoa(s.Port)},
+		{"database", s.Database},
+		{"schema", s.Schema},
+		{"schema layout", s.SchemaLayout},
+		{"user", s.User},
+		{"format", s.Format},
+		{"encoding", s.Encoding},
+		{"encoding error policy", s.EncodingError},
+		{"delimiter", s.Delimiter},
+		{"quote", s.Quote},
+		{"crlf", strconv.FormatBool(s.CRLF)},
+		{"no header", strconv.FormatBool(s.NoHeader)},
+		{"chunk parts", strconv.FormatBool(s.ChunkParts)},
+		{"batch size", strconv.Itoa(s.BatchSize)},
+		{"identity insert", strconv.FormatBool(s.IdentityInsert)},
+		{"NULL representation", s.NullRepresent},
+		{"binary encoding", s.BinaryEncoding},
+		{"unsupported column type policy", s.Unsupported},
+		{"table filters", s.Filters},
+		{"queries", s.Queries},
+	}
+}
+
+// compare returns an error naming the first setting that differs.
+func (s CheckpointSource) compare(current CheckpointSource) error {
+	want, got := s.fields(), current.fields()
+	for i := range want {
+		if want[i][1] != got[i][1] {
+			return fmt.Errorf("%s is '%s' in the checkpoint, but '%s' now", want[i][0], want[i][1], got[i][1])
+		}
+	}
+	return nil
+}
+
+// CheckpointTable is a table completed by an export.
+type CheckpointTable struct {
+	Rows        int64        `json:"rows"`
+	Files       []OutputFile `json:"files"`
+	Columns     []ColumnInfo `json:"columns"`
+	StartedAt   time.Time    `json:"started_at"`
+	CompletedAt time.Time    `json:"completed_at"`
+}
+
+// Result returns the result of the table skipped by -resume.
+func (e CheckpointTable) Result(table string) TableResult {
+	return TableResult{
+		Table:     table,
+		Rows:      e.Rows,
+		Files:     e.Files,
+		Columns:   e.Columns,
+		StartedAt: e.StartedAt,
+		Duration:  e.CompletedAt.Sub(e.StartedAt),
+		Resumed:   true,
+	}
+}
+
+// Checkpoint is written to the export directory whenever a table is
+// completed, so that an export can be resumed after it was stopped.
+type Checkpoint struct {
+	mu            sync.Mutex
+	path          string
+	DBPukeVersion string                     `json:"db_puke_version"`
+	Source        CheckpointSource           `json:"source"`
+	Tables        map[string]CheckpointTable `json:"tables"`
+}
+
+func newCheckpoint() *Checkpoint {
+	return &Checkpoint{
+		path:          filepath.Join(commandOption.OutDir, CheckpointFileName),
+		DBPukeVersion: DBPukeVersion,
+		Source:        currentCheckpointSource(),
+		Tables:        make(map[string]CheckpointTable),
+	}
+}
+
+// loadCheckpoint reads the checkpoint of the export directory for -resume.
+// It returns nil when there is none, and an error when it was written for
+// another database or other output settings.
+func loadCheckpoint() (*Checkpoint, error) {
+	c := newCheckpoint()
+	data, err := os.ReadFile(c.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// the settings left out of the file as empty must not keep the current
+	// values.
+	c.Source = CheckpointSource{}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint %s: %w", c.path, err)
+	}
+	if err := c.Source.compare(currentCheckpointSource()); err != nil {
+		return nil, fmt.Errorf("the checkpoint %s is of another export: %w", c.path, err)
+	}
+	return c, nil
+}
+
+// Completed returns the entry of table when it was completed and all of
+// its files are still there with the recorded size and checksum.
+func (c *Checkpoint) Completed(table string) (CheckpointTable, bool) {
+	if c == nil {
+		return CheckpointTable{}, false
+	}
+
+	entry, ok := c.Tables[table]
+	if !ok {
+		return CheckpointTable{}, false
+	}
+	for _, f := range entry.Files {
+		if !recordedFile(filepath.Join(commandOption.OutDir, filepath.FromSlash(f.Name)), f) {
+			return CheckpointTable{}, false
+		}
+	}
+	return entry, true
+}
+
+// recordedFile reports whether the file at path is still the file f, by
+// its size and then by its checksum.
+func recordedFile(path string, f OutputFile) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.Size() != f.Bytes {
+		return false
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return false
+	}
+	return hex.EncodeToString(hash.Sum(nil)) == f.SHA256
+}
+
+// Keep carries over a table completed by a previous export.
+func (c *Checkpoint) Keep(table string, entry CheckpointTable) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Tables[table] = entry
+}
+
+// Record adds a successfully exported table and writes the checkpoint.
+func (c *Checkpoint) Record(r TableResult) error {
+	if c == nil || r.Err != nil || r.Resumed {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Tables[r.Table] = CheckpointTable{
+		Rows:        r.Rows,
+		Files:       r.Files,
+		Columns:     r.Columns,
+		StartedAt:   r.StartedAt,
+		CompletedAt: r.StartedAt.Add(r.Duration),
+	}
+	return c.write()
+}
+
+// Save writes the checkpoint.
+func (c *Checkpoint) Save() error {
+	if c == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.write()
+}
+
+func (c *Checkpoint) write() error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(c.path, append(data, '\n'))
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckpointSourceMismatch(t *testing.T) {
+	option := *sqliteTestOption
+	option.OutDir = "testoutdir/checkpoint"
+	commandOption = &option
+	RemoveTestOutputFile(option.OutDir)
+
+	if c, err := loadCheckpoint(); c != nil || err != nil {
+		t.Fatalf("want: (nil, nil) without a checkpoint, but got (%v, %v)", c, err)
+	}
+
+	if err := newCheckpoint().Save(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadCheckpoint(); err != nil {
+		t.Errorf("want error: 'nil', but got '%s'", err)
+	}
+
+	option.Database = "testoutdir/other_database.sqlite3"
+	_, err := loadCheckpoint()
+	want := "the checkpoint " + filepath.Join(option.OutDir, CheckpointFileName) +
+		" is of another export: database is 'testoutdir/dummy_database.sqlite3' in the checkpoint, but 'testoutdir/other_database.sqlite3' now"
+	if err == nil || err.Error() != want {
+		t.Errorf("want error: '%s', but got '%v'", want, err)
+	}
+}
+
+func TestCheckpointCompleted(t *testing.T) {
+	option := *sqliteTestOption
+	option.OutDir = "testoutdir/checkpoint"
+	commandOption = &option
+	RemoveTestOutputFile(option.OutDir)
+	if err := os.MkdirAll(option.OutDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(option.OutDir, "t.csv"), []byte("id\n1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	digest := sha256.Sum256([]byte("id\n1\n"))
+	sum := hex.EncodeToString(digest[:])
+
+	c := newCheckpoint()
+	c.Record(TableResult{Table: "t", Rows: 1, Files: []OutputFile{{Name: "t.csv", Bytes: 5, SHA256: sum}}})
+	c.Record(TableResult{Table: "changed", Rows: 1, Files: []OutputFile{{Name: "t.csv", Bytes: 6, SHA256: sum}}})
+	c.Record(TableResult{Table: "edited", Rows: 1, Files: []OutputFile{{Name: "t.csv", Bytes: 5, SHA256: strings.Repeat("0", 64)}}})
+	c.Record(TableResult{Table: "missing", Rows: 1, Files: []OutputFile{{Name: "missing.csv", Bytes: 5, SHA256: sum}}})
+
+	for table, want := range map[string]bool{"t": true, "changed": false, "edited": false, "missing": false, "unknown": false} {
+		if _, ok := c.Completed(table); ok != want {
+			t.Errorf("%s: want: %v, but got %v", table, want, ok)
+		}
+	}
+}
+
+func TestCheckpointQueriesMismatch(t *testing.T) {
+	option := *sqliteTestOption
+	option.OutDir = "testoutdir/checkpoint_queries"
+	option.Queries = []NamedQuery{{Name: "a", SQL: "SELECT id FROM users WHERE id = :id"}}
+	option.Params = map[string]string{"id": "1"}
+	commandOption = &option
+	RemoveTestOutputFile(option.OutDir)
+
+	if err := newCheckpoint().Save(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadCheckpoint(); err != nil {
+		t.Errorf("want error: 'nil', but got '%s'", err)
+	}
+
+	option.Params = map[string]string{"id": "2"}
+	if _, err := loadCheckpoint(); err == nil {
+		t.Errorf("want error for other parameters, but got nil")
+	}
+
+	option.Params = map[string]string{"id": "1"}
+	option.Queries = []NamedQuery{{Name: "a", SQL: "SELECT id FROM users WHERE id = 2"}}
+	if _, err := loadCheckpoint(); err == nil {
+		t.Errorf("want error for another query, but got nil")
+	}
+}
+
+func TestCheckpointOutputPathsMismatch(t *testing.T) {
+	option := *sqliteTestOption
+	option.OutDir = "testoutdir/checkpoint_paths"
+	option.Schemas = []string{"main", "other"}
+	option.SchemaLayout = SchemaLayoutDirectory
+	commandOption = &option
+	RemoveTestOutputFile(option.OutDir)
+
+	if err := newCheckpoint().Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	option.SchemaLayout = SchemaLayoutPrefix
+	want := "schema layout is 'dir' in the checkpoint, but 'prefix' now"
+	if _, err := loadCheckpoint(); err == nil || !strings.HasSuffix(err.Error(), want) {
+		t.Errorf("want error: '%s', but got '%v'", want, err)
+	}
+
+	option.SchemaLayout = SchemaLayoutDirectory
+	option.ChunkParts = true
+	want = "chunk parts is 'false' in the checkpoint, but 'true' now"
+	if _, err := loadCheckpoint(); err == nil || !strings.HasSuffix(err.Error(), want) {
+		t.Errorf("want error: '%s', but got '%v'", want, err)
+	}
+}
+
+func TestCheckpointSQLFormatMismatch(t *testing.T) {
+	option := *sqliteTestOption
+	option.OutDir = "testoutdir/checkpoint_sql"
+	option.Format = OutputFormatSQL
+	option.SQLBatchSize = DefaultSQLBatchSize
+	option.EncodingError = EncodingErrorError
+	commandOption = &option
+	RemoveTestOutputFile(option.OutDir)
+
+	if err := newCheckpoint().Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	option.SQLBatchSize = 10
+	want := "batch size is '100' in the checkpoint, but '10' now"
+	if _, err := loadCheckpoint(); err == nil || !strings.HasSuffix(err.Error(), want) {
+		t.Errorf("want error: '%s', but got '%v'", want, err)
+	}
+
+	option.SQLBatchSize = DefaultSQLBatchSize
+	option.IdentityInsert = true
+	want = "identity insert is 'false' in the checkpoint, but 'true' now"
+	if _, err := loadCheckpoint(); err == nil || !strings.HasSuffix(err.Error(), want) {
+		t.Errorf("want error: '%s', but got '%v'", want, err)
+	}
+
+	option.IdentityInsert = false
+	option.EncodingError = EncodingErrorReplace
+	want = "encoding error policy is 'error' in the checkpoint, but 'replace' now"
+	if _, err := loadCheckpoint(); err == nil || !strings.HasSuffix(err.Error(), want) {
+		t.Errorf("want error: '%s', but got '%v'", want, err)
+	}
+}
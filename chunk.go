@@ -0,0 +1,551 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+func init() {
+	// values scanned from the database are spooled with gob, which
+	// needs to know the concrete types that are not built in.
+	gob.Register(time.Time{})
+}
+
+// KeyRange selects the rows of a chunk by the value of the chunk column.
+// Lower is inclusive and Upper exclusive. A nil bound leaves that side
+// open, so the first and the last chunk reach to the ends of the column
+// and no value can fall between chunks. IncludeNull adds the rows whose
+// key is NULL.
+type KeyRange struct {
+	Lower       any
+	Upper       any
+	IncludeNull bool
+}
+
+// rangeQuery builds the SELECT of columns of a chunk ordered by the chunk
+// column. placeholder returns the bind parameter syntax for the n-th
+// argument.
+func rangeQuery(columns string, qualifiedTable string, quotedColumn string, r KeyRange, placeholder func(n int) string) (string, []any) {
+	var conds []string
+	var args []any
+	if r.Lower != nil {
+		args = append(args, r.Lower)
+		conds = append(conds, fmt.Sprintf("%s >= %s", quotedColumn, placeholder(len(args))))
+	}
+	if r.Upper != nil {
+		args = append(args, r.Upper)
+		conds = append(conds, fmt.Sprintf("%s < %s", quotedColumn, placeholder(len(args))))
+	}
+
+	cond := strings.Join(conds, " AND ")
+	if r.IncludeNull && cond != "" {
+		cond = fmt.Sprintf("(%s OR %s IS NULL)", cond, quotedColumn)
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM %s", columns, qualifiedTable)
+	if cond != "" {
+		query += " WHERE " + cond
+	}
+	query += " ORDER BY " + quotedColumn
+
+	return query, args
+}
+
+// chunkPlan is the split of a table into key ranges.
+type chunkPlan struct {
+	table  string
+	column string
+	ranges []KeyRange
+}
+
+// planChunks splits table into -chunks ranges of its chunk column. It
+// returns nil when the table is too small to be split.
+func planChunks(ctx context.Context, operator DBPukeOperator, table string) (*chunkPlan, error) {
+	column := commandOption.ChunkColumn
+	if column == "" {
+		keys, err := operator.PrimaryKeyColumns(ctx, table)
+		if err != nil {
+			return nil, err
+		}
+		if len(keys) == 0 {
+			return nil, fmt.Errorf("no primary key to split on. use -chunk-column")
+		}
+		column = keys[0]
+	}
+
+	min, max, err := operator.ColumnRange(ctx, table, column)
+	if err != nil {
+		return nil, err
+	}
+	if min == nil || max == nil {
+		return nil, nil
+	}
+
+	ranges, err := splitKeyRange(min, max, commandOption.Chunks)
+	if err != nil {
+		return nil, fmt.Errorf("column '%s': %w", column, err)
+	}
+	if len(ranges) < 2 {
+		return nil, nil
+	}
+
+	return &chunkPlan{table: table, column: column, ranges: ranges}, nil
+}
+
+// splitKeyRange splits the values from min to max into at most n ranges
+// of about the same width. Integer and date/time columns can be split.
+func splitKeyRange(min, max any, n int) ([]KeyRange, error) {
+	lo, err := chunkKey(min)
+	if err != nil {
+		return nil, err
+	}
+	hi, err := chunkKey(max)
+	if err != nil {
+		return nil, err
+	}
+
+	var bounds []any
+	switch lo := lo.(type) {
+	case *big.Int:
+		hi, ok := hi.(*big.Int)
+		if !ok {
+			return nil, fmt.Errorf("minimum and maximum have different types")
+		}
+		// the bounds are bound as int64, as database/sql and most drivers
+		// take no larger integers.
+		if !lo.IsInt64() || !hi.IsInt64() {
+			return nil, fmt.Errorf("values beyond the range of BIGINT cannot be split into ranges")
+		}
+		for _, b := range splitBigInts(lo, hi, n) {
+			bounds = append(bounds, b.Int64())
+		}
+	case time.Time:
+		hi, ok := hi.(time.Time)
+		if !ok {
+			return nil, fmt.Errorf("minimum and maximum have different types")
+		}
+		// times are split on whole seconds, as the bounds need not be exact.
+		for _, b := range splitBigInts(big.NewInt(lo.Unix()), big.NewInt(hi.Unix()), n) {
+			t := time.Unix(b.Int64(), 0).In(lo.Location())
+			if t.After(lo) {
+				bounds = append(bounds, t)
+			}
+		}
+	}
+
+	ranges := make([]KeyRange, len(bounds)+1)
+	for i := range ranges {
+		if i > 0 {
+			ranges[i].Lower = bounds[i-1]
+		}
+		if i < len(bounds) {
+			ranges[i].Upper = bounds[i]
+		}
+	}
+	ranges[0].IncludeNull = true
+
+	return ranges, nil
+}
+
+// splitBigInts returns the n-1 inner bounds dividing lo..hi into n parts,
+// leaving out duplicates when the span is narrower than n.
+func splitBigInts(lo, hi *big.Int, n int) []*big.Int {
+	span := new(big.Int).Sub(hi, lo)
+	parts := big.NewInt(int64(n))
+
+	var bounds []*big.Int
+	prev := lo
+	for i := 1; i < n; i++ {
+		b := new(big.Int).Mul(span, big.NewInt(int64(i)))
+		b.Quo(b, parts)
+		b.Add(b, lo)
+		if b.Cmp(prev) > 0 {
+			bounds = append(bounds, b)
+			prev = b
+		}
+	}
+	return bounds
+}
+
+// chunkKey converts a MIN/MAX result to a *big.Int or a time.Time.
+// MySQL and SQLite may return both as text.
+func chunkKey(v any) (any, error) {
+	switch k := v.(type) {
+	case int64:
+		return big.NewInt(k), nil
+	case uint64:
+		return new(big.Int).SetUint64(k), nil
+	case time.Time:
+		return k, nil
+	case []byte:
+		return parseChunkKey(string(k))
+	case string:
+		return parseChunkKey(k)
+	}
+	return nil, fmt.Errorf("values of type %T cannot be split into ranges", v)
+}
+
+func parseChunkKey(s string) (any, error) {
+	if n, ok := new(big.Int).SetString(s, 10); ok {
+		return n, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05.999999999", "2006-01-02", time.RFC3339Nano} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return nil, fmt.Errorf("value '%s' cannot be split into ranges", s)
+}
+
+func chunkPartName(table string, i int) string {
+	return fmt.Sprintf("%s.part-%04d", table, i+1)
+}
+
+// chunkedExport exports the chunks of a table as separate jobs. The job
+// finishing last writes the table output and records the result.
+type chunkedExport struct {
+	parent   context.Context
+	operator DBPukeOperator
+	plan     *chunkPlan
+	result   *TableResult
+
+	mu          sync.Mutex
+	ctx         context.Context
+	cancel      context.CancelFunc
+	started     time.Time
+	remaining   int
+	rows        []int64
+	errs        []error
+	spools      []string
+	parts       []*tableOutput
+	files       []OutputFile
+	columns     []string
+	columnTypes []*sql.ColumnType
+}
+
+func newChunkedExport(ctx context.Context, operator DBPukeOperator, plan *chunkPlan, result *TableResult) *chunkedExport {
+	n := len(plan.ranges)
+	return &chunkedExport{
+		parent:    ctx,
+		operator:  operator,
+		plan:      plan,
+		result:    result,
+		remaining: n,
+		rows:      make([]int64, n),
+		errs:      make([]error, n),
+		spools:    make([]string, n),
+		parts:     make([]*tableOutput, n),
+		files:     make([]OutputFile, n),
+	}
+}
+
+func (c *chunkedExport) jobs() []func() {
+	jobs := make([]func(), len(c.plan.ranges))
+	for i := range jobs {
+		i := i
+		jobs[i] = func() { c.run(i) }
+	}
+	return jobs
+}
+
+func (c *chunkedExport) run(i int) {
+	c.mu.Lock()
+	if c.started.IsZero() {
+		// -table-timeout counts from the start of the first chunk.
+		c.ctx, c.cancel = tableContext(c.parent)
+		c.started = time.Now()
+	}
+	c.mu.Unlock()
+
+	file, err := c.exportChunk(i)
+
+	c.mu.Lock()
+	c.rows[i] = file.Rows
+	c.files[i] = file
+	c.errs[i] = err
+	c.remaining--
+	last := c.remaining == 0
+	c.mu.Unlock()
+
+	if last {
+		c.finish()
+	}
+}
+
+// exportChunk writes the rows of chunk i to its part file, or to a spool
+// file when the chunks are reassembled into one output.
+func (c *chunkedExport) exportChunk(i int) (OutputFile, error) {
+	rows, err := c.operator.QueryRecordsInRange(c.ctx, c.plan.table, c.plan.column, c.plan.ranges[i])
+	if err != nil {
+		return OutputFile{}, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return OutputFile{}, err
+	}
+	column_types, err := rows.ColumnTypes()
+	if err != nil {
+		return OutputFile{}, err
+	}
+	c.mu.Lock()
+	if c.columns == nil {
+		c.columns = columns
+		c.columnTypes = column_types
+	}
+	c.mu.Unlock()
+
+	if commandOption.ChunkParts {
+		return c.writePart(i, rows)
+	}
+	count, err := c.spool(i, rows)
+	return OutputFile{Rows: count}, err
+}
+
+// writePart writes the rows of chunk i to a temporary file, which finish
+// puts in place with the other parts only when every chunk succeeded.
+func (c *chunkedExport) writePart(i int, rows *sql.Rows) (OutputFile, error) {
+	out, err := openOutput(chunkPartName(c.plan.table, i))
+	if err != nil {
+		return OutputFile{}, err
+	}
+
+	c.mu.Lock()
+	c.parts[i] = out
+	c.mu.Unlock()
+
+	count, err := writeOutput(c.ctx, c.operator, c.plan.table, rows, out)
+	if err != nil {
+		return OutputFile{Rows: count}, err
+	}
+	return out.File(count), nil
+}
+
+func (c *chunkedExport) spool(i int, rows *sql.Rows) (int64, error) {
+	if err := os.MkdirAll(commandOption.OutDir, 0755); err != nil {
+		return 0, err
+	}
+	file, err := os.CreateTemp(commandOption.OutDir, fmt.Sprintf(".%s.chunk-*.tmp", strings.ReplaceAll(c.plan.table, string(os.PathSeparator), "_")))
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	c.mu.Lock()
+	c.spools[i] = file.Name()
+	c.mu.Unlock()
+
+	w := bufio.NewWriter(file)
+	enc := gob.NewEncoder(w)
+
+	values := make([]any, len(c.columns))
+	valuePtrs := make([]any, len(c.columns))
+	for j := range values {
+		valuePtrs[j] = &values[j]
+	}
+
+	var count int64
+	for rows.Next() {
+		if err := rows.Scan(valuePtrs...); err != nil {
+			return count, err
+		}
+		if err := enc.Encode(values); err != nil {
+			return count, err
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return count, err
+	}
+
+	if err := w.Flush(); err != nil {
+		return count, err
+	}
+	return count, file.Close()
+}
+
+func (c *chunkedExport) finish() {
+	defer c.cancel()
+	defer c.removeSpools()
+	defer c.abortParts()
+
+	var total int64
+	var err error
+	for i := range c.rows {
+		total += c.rows[i]
+		if err == nil && c.errs[i] != nil {
+			err = fmt.Errorf("chunk %d: %w", i+1, c.errs[i])
+		}
+	}
+
+	files := c.files
+	if err == nil && commandOption.ChunkParts {
+		err = c.commitParts()
+	} else if err == nil {
+		var file OutputFile
+		file, err = c.reassemble()
+		total = file.Rows
+		files = []OutputFile{file}
+	}
+	if err != nil {
+		// the parts are put in place only when every chunk succeeded.
+		files = nil
+		err = cancelError(c.ctx, err)
+		fmt.Fprintf(os.Stderr, "Export failed: '%s' %s\n", c.plan.table, err)
+	}
+
+	finishTable(c.result, TableResult{
+		Table:     c.plan.table,
+		Rows:      total,
+		Files:     outputFiles(files...),
+		Columns:   columnInfos(c.operator, c.columnTypes),
+		StartedAt: c.started,
+		Duration:  time.Since(c.started),
+		Err:       err,
+	})
+}
+
+// reassemble writes the spooled chunks in key order to the table output.
+func (c *chunkedExport) reassemble() (OutputFile, error) {
+	out, err := openOutput(c.plan.table)
+	if err != nil {
+		return OutputFile{}, err
+	}
+	defer out.Abort()
+
+	writer, err := newOutputWriter(c.ctx, c.operator, c.plan.table, c.columnTypes, out)
+	if err != nil {
+		return OutputFile{}, err
+	}
+	if err := writer.WriteHeader(c.columns); err != nil {
+		return OutputFile{}, err
+	}
+
+	var count int64
+	for _, spool := range c.spools {
+		n, err := c.replay(spool, writer)
+		count += n
+		if err != nil {
+			return OutputFile{Rows: count}, err
+		}
+	}
+
+	if err := writer.Flush(); err != nil {
+		return OutputFile{Rows: count}, err
+	}
+	if err := out.Close(); err != nil {
+		return OutputFile{Rows: count}, err
+	}
+	return out.File(count), nil
+}
+
+func (c *chunkedExport) replay(spool string, writer OutputWriter) (int64, error) {
+	file, err := os.Open(spool)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	dec := gob.NewDecoder(bufio.NewReader(file))
+	var count int64
+	for {
+		if err := c.ctx.Err(); err != nil {
+			return count, err
+		}
+
+		var values []any
+		if err := dec.Decode(&values); err == io.EOF {
+			return count, nil
+		} else if err != nil {
+			return count, err
+		}
+
+		if err := writeOutputRecord(c.operator, c.columnTypes, values, writer); err != nil {
+			return count, err
+		}
+		count++
+	}
+}
+
+// commitParts puts the parts in place, replacing those of the previous
+// export, and removes the parts beyond them left by a previous export
+// with more chunks.
+func (c *chunkedExport) commitParts() error {
+	for i, part := range c.parts {
+		if err := part.Commit(); err != nil {
+			return fmt.Errorf("part %d: %w", i+1, err)
+		}
+	}
+	if sharedWorkbook != nil {
+		return nil
+	}
+
+	for i := len(c.parts); ; i++ {
+		path, err := getOutputFilePath(commandOption.OutDir, tableFileName(chunkPartName(c.plan.table, i)))
+		if err != nil {
+			return err
+		}
+		if err := os.Remove(path); errors.Is(err, os.ErrNotExist) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("part %d of the previous export: %w", i+1, err)
+		}
+	}
+}
+
+// abortParts removes the parts not put in place, so that a failed or
+// canceled export leaves the previous parts as they were.
+func (c *chunkedExport) abortParts() {
+	for _, part := range c.parts {
+		if part != nil {
+			part.Abort()
+		}
+	}
+}
+
+func (c *chunkedExport) removeSpools() {
+	for _, spool := range c.spools {
+		if spool != "" {
+			os.Remove(spool)
+		}
+	}
+}
+
+// queryPrimaryKeyColumns runs a query returning the primary key columns
+// of a table in key order.
+func queryPrimaryKeyColumns(ctx context.Context, db *sql.DB, query string, args ...any) ([]string, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var columns []string
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			return nil, err
+		}
+		columns = append(columns, column)
+	}
+	return columns, rows.Err()
+}
+
+// queryColumnRange returns the smallest and the largest value of a column.
+// Both are nil when the column has no values.
+func queryColumnRange(ctx context.Context, db queryer, qualifiedTable string, quotedColumn string) (min, max any, err error) {
+	query := fmt.Sprintf("SELECT MIN(%s), MAX(%s) FROM %s", quotedColumn, quotedColumn, qualifiedTable)
+	err = db.QueryRowContext(ctx, query).Scan(&min, &max)
+	return min, max, err
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSplitKeyRange(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, 3, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name     string
+		min, max any
+		n        int
+		want     []any
+	}{
+		{"integers", int64(1), int64(100), 4, []any{int64(25), int64(50), int64(75)}},
+		{"narrower than chunks", int64(1), int64(3), 4, []any{int64(2)}},
+		{"single value", int64(7), int64(7), 4, nil},
+		{"int64 extremes", int64(math.MinInt64), int64(math.MaxInt64), 2, []any{int64(-1)}},
+		{"uint64 within int64", uint64(0), uint64(math.MaxInt64), 2, []any{int64(math.MaxInt64 / 2)}},
+		{"text integers", []byte("10"), []byte("30"), 2, []any{int64(20)}},
+		{"times", day(1), day(5), 4, []any{day(2), day(3), day(4)}},
+		{"text times", "2025-03-01 00:00:00", "2025-03-03 00:00:00", 2, []any{day(2)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ranges, err := splitKeyRange(tt.min, tt.max, tt.n)
+			if err != nil {
+				t.Fatalf("want error: 'nil', but got '%s'", err)
+			}
+
+			if len(ranges) != len(tt.want)+1 {
+				t.Fatalf("want ranges: %d, but got %d", len(tt.want)+1, len(ranges))
+			}
+			if ranges[0].Lower != nil || ranges[len(ranges)-1].Upper != nil {
+				t.Errorf("want the first and the last range to be open, but got %v", ranges)
+			}
+			if !ranges[0].IncludeNull {
+				t.Errorf("want the first range to include NULL")
+			}
+			for i, b := range tt.want {
+				if !reflect.DeepEqual(ranges[i].Upper, b) || !reflect.DeepEqual(ranges[i+1].Lower, b) {
+					t.Errorf("want bound %d: %v, but got upper %v and lower %v", i, b, ranges[i].Upper, ranges[i+1].Lower)
+				}
+			}
+		})
+	}
+}
+
+func TestSplitKeyRangeUnsupported(t *testing.T) {
+	if _, err := splitKeyRange("abc", "xyz", 2); err == nil {
+		t.Errorf("want error for text keys, but got nil")
+	}
+	if _, err := splitKeyRange(1.5, 2.5, 2); err == nil {
+		t.Errorf("want error for float keys, but got nil")
+	}
+	if _, err := splitKeyRange(uint64(0), uint64(math.MaxUint64), 2); err == nil {
+		t.Errorf("want error for keys beyond int64, but got nil")
+	}
+	if _, err := splitKeyRange("0", "100000000000000000000", 2); err == nil {
+		t.Errorf("want error for text keys beyond int64, but got nil")
+	}
+}
+
+func TestRangeQuery(t *testing.T) {
+	placeholder := func(n int) string { return fmt.Sprintf("$%d", n) }
+
+	tests := []struct {
+		name      string
+		r         KeyRange
+		wantQuery string
+		wantArgs  []any
+	}{
+		{"first", KeyRange{Upper: int64(10), IncludeNull: true},
+			`SELECT * FROM t WHERE ("k" < $1 OR "k" IS NULL) ORDER BY "k"`, []any{int64(10)}},
+		{"middle", KeyRange{Lower: int64(10), Upper: int64(20)},
+			`SELECT * FROM t WHERE "k" >= $1 AND "k" < $2 ORDER BY "k"`, []any{int64(10), int64(20)}},
+		{"last", KeyRange{Lower: int64(20)},
+			`SELECT * FROM t WHERE "k" >= $1 ORDER BY "k"`, []any{int64(20)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, args := rangeQuery("*", "t", `"k"`, tt.r, placeholder)
+			if query != tt.wantQuery {
+				t.Errorf("want: %s, but got %s", tt.wantQuery, query)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("want: %v, but got %v", tt.wantArgs, args)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bufio"
+	"database/sql"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	CSVQuoteMinimal    = "minimal"
+	CSVQuoteAll        = "all"
+	CSVQuoteNonNumeric = "non-numeric"
+)
+
+// CSVWriter writes records in the CSV dialect chosen by the command options.
+// encoding/csv is not used because it cannot quote every field.
+type CSVWriter struct {
+	kinds     []ColumnKind
+	delimiter rune
+	quote     string
+	newline   string
+	header    bool
+	writer    *bufio.Writer
+}
+
+func NewCSVWriter(operator DBPukeOperator, columnTypes []*sql.ColumnType, w io.Writer) *CSVWriter {
+	kinds := make([]ColumnKind, len(columnTypes))
+	for i, ty := range columnTypes {
+		kinds[i] = operator.ColumnKind(ty)
+	}
+
+	delimiter := commandOption.Delimiter
+	if delimiter == 0 {
+		delimiter = ','
+	}
+	quote := commandOption.Quote
+	if quote == "" {
+		quote = CSVQuoteMinimal
+	}
+	newline := "\n"
+	if commandOption.CRLF {
+		newline = "\r\n"
+	}
+
+	return &CSVWriter{
+		kinds:     kinds,
+		delimiter: delimiter,
+		quote:     quote,
+		newline:   newline,
+		header:    !commandOption.NoHeader,
+		writer:    bufio.NewWriter(w),
+	}
+}
+
+func (w *CSVWriter) WriteHeader(columns []string) error {
+	if !w.header {
+		return nil
+	}
+
+	quoted := make([]bool, len(columns))
+	for i, c := range columns {
+		quoted[i] = w.quote != CSVQuoteMinimal || w.fieldNeedsQuotes(c)
+	}
+	return w.writeLine(columns, quoted)
+}
+
+func (w *CSVWriter) WriteRecord(record []string, values []any) error {
+	quoted := make([]bool, len(record))
+	for i, field := range record {
+		switch w.quote {
+		case CSVQuoteAll:
+			quoted[i] = true
+		case CSVQuoteNonNumeric:
+			// NULL is left unquoted so that it can be told apart from a string.
+			quoted[i] = values[i] != nil && !w.isNumeric(field, w.kinds[i])
+		default:
+			quoted[i] = w.fieldNeedsQuotes(field)
+		}
+	}
+	return w.writeLine(record, quoted)
+}
+
+func (w *CSVWriter) Flush() error {
+	return w.writer.Flush()
+}
+
+func (w *CSVWriter) writeLine(fields []string, quoted []bool) error {
+	for i, field := range fields {
+		if i > 0 {
+			if _, err := w.writer.WriteRune(w.delimiter); err != nil {
+				return err
+			}
+		}
+
+		if !quoted[i] {
+			if _, err := w.writer.WriteString(field); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if _, err := w.writer.WriteString(`"` + strings.ReplaceAll(field, `"`, `""`) + `"`); err != nil {
+			return err
+		}
+	}
+
+	_, err := w.writer.WriteString(w.newline)
+	return err
+}
+
+// fieldNeedsQuotes reports whether field must be quoted to be read back,
+// following the rules of encoding/csv.
+func (w *CSVWriter) fieldNeedsQuotes(field string) bool {
+	if field == "" {
+		return false
+	}
+	if field == `\.` {
+		return true
+	}
+	if strings.ContainsRune(field, w.delimiter) || strings.ContainsAny(field, "\"\r\n") {
+		return true
+	}
+
+	r, _ := utf8.DecodeRuneInString(field)
+	return unicode.IsSpace(r)
+}
+
+func (w *CSVWriter) isNumeric(field string, kind ColumnKind) bool {
+	switch kind {
+	case ColumnKindInteger, ColumnKindFloat, ColumnKindDecimal:
+		return isNumberLiteral(field)
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+)
+
+func TestCSVWriterDialect(t *testing.T) {
+	tests := []struct {
+		name      string
+		delimiter rune
+		quote     string
+		newline   string
+		header    bool
+		want      string
+	}{
+		{"minimal", ',', CSVQuoteMinimal, "\n", true,
+			"id,name\n1,\"a,b\"\n2,\" x\"\nNULL,\"say \"\"hi\"\"\"\n"},
+		{"all", '\t', CSVQuoteAll, "\r\n", true,
+			"\"id\"\t\"name\"\r\n\"1\"\t\"a,b\"\r\n\"2\"\t\" x\"\r\n\"NULL\"\t\"say \"\"hi\"\"\"\r\n"},
+		{"non-numeric", '|', CSVQuoteNonNumeric, "\n", false,
+			"1|\"a,b\"\n2|\" x\"\nNULL|\"say \"\"hi\"\"\"\n"},
+		{"minimal with tab", '\t', CSVQuoteMinimal, "\n", false,
+			"1\ta,b\n2\t\" x\"\nNULL\t\"say \"\"hi\"\"\"\n"},
+	}
+
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		w := &CSVWriter{
+			kinds:     []ColumnKind{ColumnKindInteger, ColumnKindString},
+			delimiter: tt.delimiter,
+			quote:     tt.quote,
+			newline:   tt.newline,
+			header:    tt.header,
+			writer:    bufio.NewWriter(&buf),
+		}
+
+		if err := w.WriteHeader([]string{"id", "name"}); err != nil {
+			t.Fatalf("%s: WriteHeader want error: nil, but got '%s'", tt.name, err)
+		}
+		records := [][]string{{"1", "a,b"}, {"2", " x"}, {"NULL", `say "hi"`}}
+		values := [][]any{{int64(1), "a,b"}, {int64(2), " x"}, {nil, `say "hi"`}}
+		for i := range records {
+			if err := w.WriteRecord(records[i], values[i]); err != nil {
+				t.Fatalf("%s: WriteRecord want error: nil, but got '%s'", tt.name, err)
+			}
+		}
+		if err := w.Flush(); err != nil {
+			t.Fatalf("%s: Flush want error: nil, but got '%s'", tt.name, err)
+		}
+
+		if buf.String() != tt.want {
+			t.Errorf("%s: want: %q, but got %q", tt.name, tt.want, buf.String())
+		}
+	}
+}
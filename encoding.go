@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+const (
+	EncodingUTF8     = "utf-8"
+	EncodingUTF8BOM  = "utf-8-bom"
+	EncodingShiftJIS = "shift_jis"
+	EncodingEUCJP    = "euc-jp"
+	EncodingUTF16    = "utf-16"
+	EncodingUTF16LE  = "utf-16le"
+	EncodingUTF16BE  = "utf-16be"
+
+	EncodingErrorError   = "error"
+	EncodingErrorReplace = "replace"
+	EncodingErrorHTML    = "html"
+)
+
+// outputEncodings maps the -encoding names to their encodings.
+// utf-16 is little endian with a BOM, as expected by Excel and Windows tools.
+var outputEncodings = map[string]encoding.Encoding{
+	EncodingUTF8BOM:  unicode.UTF8BOM,
+	EncodingShiftJIS: japanese.ShiftJIS,
+	EncodingEUCJP:    japanese.EUCJP,
+	EncodingUTF16:    unicode.UTF16(unicode.LittleEndian, unicode.UseBOM),
+	EncodingUTF16LE:  unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM),
+	EncodingUTF16BE:  unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM),
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+// newEncodingWriter returns a writer transcoding UTF-8 to the encoding
+// chosen by -encoding. Close must be called to write out buffered bytes.
+func newEncodingWriter(w io.Writer) io.WriteCloser {
+	name := commandOption.Encoding
+	enc, ok := outputEncodings[name]
+	if !ok {
+		return nopWriteCloser{w}
+	}
+
+	t := &unsupportedRuneHandler{
+		Transformer: enc.NewEncoder(),
+		encoding:    name,
+		policy:      commandOption.EncodingError,
+	}
+	return transform.NewWriter(w, t)
+}
+
+// repertoireError is implemented by the errors x/text encoders return for
+// runes the encoding cannot represent.
+type repertoireError interface {
+	Replacement() byte
+}
+
+// unsupportedRuneHandler applies the -encoding-error policy to runes the
+// encoder cannot represent, in the same way as encoding.ReplaceUnsupported.
+type unsupportedRuneHandler struct {
+	transform.Transformer
+	encoding string
+	policy   string
+}
+
+func (h *unsupportedRuneHandler) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	nDst, nSrc, err = h.Transformer.Transform(dst, src, atEOF)
+	for err != nil {
+		if _, ok := err.(repertoireError); !ok {
+			return nDst, nSrc, err
+		}
+
+		r, size := utf8.DecodeRune(src[nSrc:])
+		var replacement string
+		switch h.policy {
+		case EncodingErrorReplace:
+			replacement = "?"
+		case EncodingErrorHTML:
+			replacement = fmt.Sprintf("&#%d;", r)
+		default:
+			return nDst, nSrc, fmt.Errorf("character %q (U+%04X) cannot be encoded in %s", r, r, h.encoding)
+		}
+		if len(dst[nDst:]) < len(replacement) {
+			return nDst, nSrc, transform.ErrShortDst
+		}
+		// only the Japanese encodings reject runes, and they keep ASCII as is.
+		nDst += copy(dst[nDst:], replacement)
+		nSrc += size
+
+		err = nil
+		if nSrc < len(src) {
+			var dn, sn int
+			dn, sn, err = h.Transformer.Transform(dst[nDst:], src[nSrc:], atEOF)
+			nDst += dn
+			nSrc += sn
+		}
+	}
+	return nDst, nSrc, nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEncodingWriter(t *testing.T) {
+	tests := []struct {
+		encoding string
+		policy   string
+		in       string
+		want     []byte
+	}{
+		{EncodingUTF8, EncodingErrorError, "テスト😀", []byte("テスト😀")},
+		{EncodingUTF8BOM, EncodingErrorError, "テスト", []byte("\xef\xbb\xbfテスト")},
+		{EncodingShiftJIS, EncodingErrorError, "ﾃｽﾄ,テスト", []byte("\xc3\xbd\xc4,\x83\x65\x83\x58\x83\x67")},
+		{EncodingEUCJP, EncodingErrorError, "テスト", []byte("\xa5\xc6\xa5\xb9\xa5\xc8")},
+		{EncodingUTF16, EncodingErrorError, "aテ", []byte("\xff\xfea\x00\xc6\x30")},
+		{EncodingUTF16LE, EncodingErrorError, "aテ", []byte("a\x00\xc6\x30")},
+		{EncodingUTF16BE, EncodingErrorError, "aテ", []byte("\x00a\x30\xc6")},
+		{EncodingShiftJIS, EncodingErrorReplace, "a😀b", []byte("a?b")},
+		{EncodingShiftJIS, EncodingErrorHTML, "a😀b", []byte("a&#128512;b")},
+		{EncodingEUCJP, EncodingErrorReplace, "😀テ", []byte("?\xa5\xc6")},
+	}
+
+	for _, tt := range tests {
+		commandOption = &Option{Encoding: tt.encoding, EncodingError: tt.policy}
+
+		var buf bytes.Buffer
+		w := newEncodingWriter(&buf)
+		if _, err := w.Write([]byte(tt.in)); err != nil {
+			t.Fatalf("%s(%s) Write want error: nil, but got '%s'", tt.encoding, tt.policy, err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("%s(%s) Close want error: nil, but got '%s'", tt.encoding, tt.policy, err)
+		}
+
+		if !bytes.Equal(buf.Bytes(), tt.want) {
+			t.Errorf("%s(%s) want: %x, but got %x", tt.encoding, tt.policy, tt.want, buf.Bytes())
+		}
+	}
+}
+
+func TestEncodingWriterUnsupportedRune(t *testing.T) {
+	commandOption = &Option{Encoding: EncodingShiftJIS, EncodingError: EncodingErrorError}
+
+	var buf bytes.Buffer
+	w := newEncodingWriter(&buf)
+	_, err := w.Write([]byte("a😀b"))
+	if err == nil {
+		err = w.Close()
+	}
+
+	want := "character '😀' (U+1F600) cannot be encoded in shift_jis"
+	if err == nil || err.Error() != want {
+		t.Errorf("want error: '%s', but got '%v'", want, err)
+	}
+}
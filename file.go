@@ -1,61 +1,304 @@
 package main
 
 import (
+	"context"
+	"crypto/sha256"
 	"database/sql"
-	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"hash"
+	"io"
 	"os"
 	"path/filepath"
 )
 
+// OutputWriter writes the exported records of a table in a specific output format.
+type OutputWriter interface {
+	WriteHeader(columns []string) error
+	// WriteRecord receives both the values formatted by the operator and
+	// the raw values scanned from the database (nil for NULL).
+	WriteRecord(record []string, values []any) error
+	Flush() error
+}
+
 func getOutputFilePath(outdir, tableName string) (string, error) {
 	absPath, err := filepath.Abs(outdir)
 	if err != nil {
 		return "", fmt.Errorf("error retrieving output directory path: %w", err)
 	}
 
-	if _, err := os.Stat(absPath); os.IsNotExist(err) {
-		if err := os.MkdirAll(absPath, 0755); err != nil {
+	filePath := filepath.Join(absPath, fmt.Sprintf("%s.%s", tableName, outputFileExtension()))
+
+	if _, err := os.Stat(filepath.Dir(filePath)); os.IsNotExist(err) {
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
 			return "", fmt.Errorf("error creating output directory: %w", err)
 		}
 	}
 
-	filePath := filepath.Join(absPath, fmt.Sprintf("%s.csv", tableName))
-
 	return filePath, nil
 }
 
-func createOutputFile(table string) (*os.File, error) {
-	fileName, err := getOutputFilePath(commandOption.OutDir, table)
+func outputFileExtension() string {
+	switch commandOption.Format {
+	case OutputFormatJSONL:
+		return "jsonl"
+	case OutputFormatParquet:
+		return "parquet"
+	case OutputFormatSQL:
+		return "sql"
+	case OutputFormatXLSX:
+		return "xlsx"
+	default:
+		return "csv"
+	}
+}
+
+// atomicFile is an output file written to a temporary file in the output
+// directory and renamed into place by Commit, so that readers never see a
+// partially written file and a failed export keeps the previous one.
+type atomicFile struct {
+	*os.File
+	path string
+}
+
+// createOutputFile creates the output file of a table, or of a part of it.
+func createOutputFile(table string) (*atomicFile, error) {
+	fileName, err := getOutputFilePath(commandOption.OutDir, tableFileName(table))
 	if err != nil {
 		return nil, err
 	}
 
-	file, err := os.Create(fileName)
+	return createAtomicFile(fileName)
+}
+
+func createAtomicFile(path string) (*atomicFile, error) {
+	file, err := os.CreateTemp(filepath.Dir(path), fmt.Sprintf(".%s.*.tmp", filepath.Base(path)))
 	if err != nil {
 		return nil, err
 	}
 
-	return file, err
+	return &atomicFile{File: file, path: path}, nil
+}
+
+// Commit flushes the file to disk and renames it to the output file.
+func (f *atomicFile) Commit() error {
+	if err := f.Finish(); err != nil {
+		return err
+	}
+	return f.Rename()
+}
+
+// Finish flushes the file to disk and closes it without renaming it, so
+// that files replacing each other's previous version can all be written
+// before the first of them is renamed.
+func (f *atomicFile) Finish() error {
+	err := f.Chmod(0644)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.File.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
+
+// Rename renames the file finished by Finish to the output file.
+func (f *atomicFile) Rename() error {
+	err := os.Rename(f.Name(), f.path)
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
+
+// Abort removes the file without touching the output file.
+func (f *atomicFile) Abort() {
+	f.File.Close()
+	os.Remove(f.Name())
+}
+
+// writeFileAtomic replaces the file at path with data.
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	file, err := createAtomicFile(path)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Abort()
+		return err
+	}
+	return file.Commit()
+}
+
+// OutputFile describes a written output file. Name is relative to the
+// export directory.
+type OutputFile struct {
+	Name   string `json:"name"`
+	Rows   int64  `json:"rows"`
+	Bytes  int64  `json:"bytes"`
+	SHA256 string `json:"sha256"`
+}
+
+// outputDigest counts and hashes the bytes written to an output file.
+type outputDigest struct {
+	w     io.Writer
+	hash  hash.Hash
+	bytes int64
+}
+
+func newOutputDigest(w io.Writer) *outputDigest {
+	return &outputDigest{w: w, hash: sha256.New()}
 }
 
-func writeOutputHeader(rows *sql.Rows, writer *csv.Writer) error {
+func (d *outputDigest) Write(p []byte) (int, error) {
+	n, err := d.w.Write(p)
+	d.hash.Write(p[:n])
+	d.bytes += int64(n)
+	return n, err
+}
+
+// file describes the file at path written through the digest.
+func (d *outputDigest) file(path string, rows int64) OutputFile {
+	return OutputFile{
+		Name:   outputFileName(path),
+		Rows:   rows,
+		Bytes:  d.bytes,
+		SHA256: hex.EncodeToString(d.hash.Sum(nil)),
+	}
+}
+
+// tableOutput is the output file of a table. The writer transcodes to the
+// -encoding and Close must be called after the output writer is flushed
+// to put the file in place, or Finish and later Commit. Tables of a shared
+// workbook have no file of their own, so the writer is nil then and Close
+// does nothing.
+type tableOutput struct {
+	io.Writer
+	file     *atomicFile
+	digest   *outputDigest
+	encoder  io.WriteCloser
+	finished bool
+	done     bool
+}
+
+func openOutput(name string) (*tableOutput, error) {
+	if sharedWorkbook != nil {
+		return &tableOutput{}, nil
+	}
+
+	file, err := createOutputFile(name)
+	if err != nil {
+		return nil, err
+	}
+	digest := newOutputDigest(file)
+	encoder := newEncodingWriter(digest)
+
+	return &tableOutput{Writer: encoder, file: file, digest: digest, encoder: encoder}, nil
+}
+
+// File describes the file put in place by Close with the rows written to
+// it. Tables of a shared workbook have no name.
+func (o *tableOutput) File(rows int64) OutputFile {
+	if o.file == nil {
+		return OutputFile{Rows: rows}
+	}
+	return o.digest.file(o.file.path, rows)
+}
+
+// outputFileName returns path relative to the export directory.
+func outputFileName(path string) string {
+	if outdir, err := filepath.Abs(commandOption.OutDir); err == nil {
+		if name, err := filepath.Rel(outdir, path); err == nil {
+			return filepath.ToSlash(name)
+		}
+	}
+	return filepath.Base(path)
+}
+
+func (o *tableOutput) Close() error {
+	if err := o.Finish(); err != nil {
+		return err
+	}
+	return o.Commit()
+}
+
+// Finish completes the file without putting it in place yet.
+func (o *tableOutput) Finish() error {
+	if o.file == nil || o.done || o.finished {
+		return nil
+	}
+	o.finished = true
+
+	err := o.encoder.Close()
+	if err == nil {
+		err = o.file.Finish()
+	}
+	if err != nil {
+		o.Abort()
+	}
+	return err
+}
+
+// Commit puts the file completed by Finish in place.
+func (o *tableOutput) Commit() error {
+	if o.file == nil || o.done {
+		return nil
+	}
+	o.done = true
+
+	return o.file.Rename()
+}
+
+// Abort removes the file unless Close or Commit has put it in place, so
+// that a failed or canceled export leaves the previous output as it was.
+func (o *tableOutput) Abort() {
+	if o.file == nil || o.done {
+		return
+	}
+	o.done = true
+	o.file.Abort()
+}
+
+func newOutputWriter(ctx context.Context, operator DBPukeOperator, table string, columnTypes []*sql.ColumnType, w io.Writer) (OutputWriter, error) {
+	switch commandOption.Format {
+	case OutputFormatJSONL:
+		return NewJSONLWriter(operator, columnTypes, w), nil
+	case OutputFormatParquet:
+		return NewParquetWriter(operator, columnTypes, w)
+	case OutputFormatSQL:
+		return NewSQLWriter(ctx, operator, table, columnTypes, w)
+	case OutputFormatXLSX:
+		return NewXLSXWriter(operator, table, columnTypes, w)
+	default:
+		return NewCSVWriter(operator, columnTypes, w), nil
+	}
+}
+
+func writeOutputHeader(rows *sql.Rows, writer OutputWriter) error {
 	columns, err := rows.Columns()
 	if err != nil {
 		return err
 	}
 
-	if err := writer.Write(columns); err != nil {
+	if err := writer.WriteHeader(columns); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func writeOutputBody(operator DBPukeOperator, rows *sql.Rows, writer *csv.Writer) error {
+// writeOutputBody writes all rows and returns the number of rows written.
+func writeOutputBody(ctx context.Context, operator DBPukeOperator, rows *sql.Rows, writer OutputWriter) (int64, error) {
 	column_types, err := rows.ColumnTypes()
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	values := make([]interface{}, len(column_types))
@@ -65,25 +308,44 @@ func writeOutputBody(operator DBPukeOperator, rows *sql.Rows, writer *csv.Writer
 		valuePtrs[i] = &values[i]
 	}
 
+	var count int64
 	for rows.Next() {
+		if err := ctx.Err(); err != nil {
+			return count, err
+		}
 		if err := rows.Scan(valuePtrs...); err != nil {
-			return err
+			return count, err
 		}
 
-		var record []string
-		for i, val := range values {
-			ty := column_types[i]
-			val_str, err := operator.FormatData(val, ty)
-			if err != nil {
-				return err
-			}
-			record = append(record, val_str)
+		if err := writeOutputRecord(operator, column_types, values, writer); err != nil {
+			return count, err
 		}
+		count++
+	}
 
-		if err := writer.Write(record); err != nil {
+	return count, rows.Err()
+}
+
+// writeOutputRecord formats the values of a row and writes them.
+func writeOutputRecord(operator DBPukeOperator, columnTypes []*sql.ColumnType, values []any, writer OutputWriter) error {
+	var record []string
+	for i, val := range values {
+		ty := columnTypes[i]
+		val_str, err := operator.FormatData(val, ty)
+		if err != nil {
 			return err
 		}
+		record = append(record, val_str)
 	}
 
-	return nil
+	return writer.WriteRecord(record, values)
+}
+
+// isNumberLiteral reports whether s is a plain decimal number such as
+// "-12.5" or "1e+20", which can be written without quotes in JSON and SQL.
+func isNumberLiteral(s string) bool {
+	if s == "" || !(s[0] == '-' || ('0' <= s[0] && s[0] <= '9')) {
+		return false
+	}
+	return json.Valid([]byte(s))
 }
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// TableFilter narrows the records exported from a table.
+type TableFilter struct {
+	Where   string        `json:"where,omitempty"`
+	OrderBy []OrderColumn `json:"order_by,omitempty"`
+	Limit   int64         `json:"limit,omitempty"`
+}
+
+// OrderColumn is a column of the ORDER BY of a table filter.
+type OrderColumn struct {
+	Name string `json:"name"`
+	Desc bool   `json:"desc,omitempty"`
+}
+
+// tableConfigFile is the file of -table-config:
+//
+//	{"tables": {"orders": {"where": "created_at >= :since", "order_by": "created_at DESC, id", "limit": 1000}}}
+type tableConfigFile struct {
+	Tables map[string]struct {
+		Where   string `json:"where"`
+		OrderBy string `json:"order_by"`
+		Limit   int64  `json:"limit"`
+	} `json:"tables"`
+}
+
+// parseTableFilters builds the filters of the tables from -table-config,
+// overridden by -where, -order-by and -table-limit.
+func parseTableFilters(option *Option) (map[string]*TableFilter, error) {
+	filters := make(map[string]*TableFilter)
+	filter := func(table string) *TableFilter {
+		if filters[table] == nil {
+			filters[table] = &TableFilter{}
+		}
+		return filters[table]
+	}
+
+	if option.TableConfig != "" {
+		config, err := readTableConfig(option.TableConfig)
+		if err != nil {
+			return nil, fmt.Errorf("error: cannot read the table config (-table-config %s). %s\n", option.TableConfig, err)
+		}
+		for table, c := range config.Tables {
+			f := filter(table)
+			f.Where = strings.TrimSpace(c.Where)
+			if f.OrderBy, err = parseOrderBy(c.OrderBy); err != nil {
+				return nil, fmt.Errorf("error: invalid order of '%s' (-table-config %s). %s\n", table, option.TableConfig, err)
+			}
+			if c.Limit < 0 {
+				return nil, fmt.Errorf("error: invalid limit of '%s' (-table-config %s). specify 0 or more\n", table, option.TableConfig)
+			}
+			f.Limit = c.Limit
+		}
+	}
+
+	for _, opstr := range option.Wheres {
+		table, where, ok := cutTableOption(opstr)
+		if !ok {
+			return nil, fmt.Errorf("error: invalid condition '%s' (-where). use <table>=<condition>\n", opstr)
+		}
+		filter(table).Where = where
+	}
+	for _, opstr := range option.OrderBys {
+		table, orderBy, ok := cutTableOption(opstr)
+		columns, err := parseOrderBy(orderBy)
+		if !ok || err != nil || len(columns) == 0 {
+			return nil, fmt.Errorf("error: invalid order '%s' (-order-by). use <table>=<column> [ASC|DESC], ...\n", opstr)
+		}
+		filter(table).OrderBy = columns
+	}
+	for _, opstr := range option.TableLimits {
+		table, limit, ok := cutTableOption(opstr)
+		n, err := strconv.ParseInt(limit, 10, 64)
+		if !ok || err != nil || n < 1 {
+			return nil, fmt.Errorf("error: invalid limit '%s' (-table-limit). use <table>=<rows>\n", opstr)
+		}
+		filter(table).Limit = n
+	}
+
+	for table, f := range filters {
+		if err := checkCondition(f.Where); err != nil {
+			return nil, fmt.Errorf("error: invalid condition of '%s' (-where, -table-config). %s\n", table, err)
+		}
+	}
+	return filters, nil
+}
+
+func readTableConfig(name string) (*tableConfigFile, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	config := &tableConfigFile{}
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// cutTableOption splits an option written as <table>=<value>.
+func cutTableOption(opstr string) (table, value string, ok bool) {
+	table, value, ok = strings.Cut(opstr, "=")
+	table, value = strings.TrimSpace(table), strings.TrimSpace(value)
+	return table, value, ok && table != "" && value != ""
+}
+
+// parseOrderBy parses columns written as <column> [ASC|DESC], ... The
+// columns are quoted by the operator, so they are names, not expressions.
+func parseOrderBy(s string) ([]OrderColumn, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+
+	var columns []OrderColumn
+	for _, item := range strings.Split(s, ",") {
+		fields := strings.Fields(item)
+		if len(fields) == 0 || len(fields) > 2 {
+			return nil, fmt.Errorf("invalid column '%s'", strings.TrimSpace(item))
+		}
+		column := OrderColumn{Name: fields[0]}
+		if len(fields) == 2 {
+			switch strings.ToUpper(fields[1]) {
+			case "ASC":
+			case "DESC":
+				column.Desc = true
+			default:
+				return nil, fmt.Errorf("invalid direction '%s'", fields[1])
+			}
+		}
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
+
+// checkCondition rejects a WHERE condition which could end the SELECT it
+// is put in: a statement separator, a comment, an unterminated string or
+// an unbalanced parenthesis.
+func checkCondition(cond string) error {
+	depth := 0
+	for i := 0; i < len(cond); i++ {
+		switch c := cond[i]; {
+		case c == '\'' || c == '"' || c == '`' || c == '[':
+			closing := c
+			if c == '[' {
+				closing = ']'
+			}
+			n := strings.IndexByte(cond[i+1:], closing)
+			if n < 0 {
+				return fmt.Errorf("unterminated %c", c)
+			}
+			i += 1 + n
+		case c == ';':
+			return fmt.Errorf("';' is not allowed")
+		case strings.HasPrefix(cond[i:], "--") || strings.HasPrefix(cond[i:], "/*"):
+			return fmt.Errorf("comments are not allowed")
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth < 0 {
+				return fmt.Errorf("unbalanced ')'")
+			}
+		}
+	}
+	if depth != 0 {
+		return fmt.Errorf("unbalanced '('")
+	}
+	return nil
+}
+
+// tableFilter returns the filter of table, with -limit for the tables
+// without their own limit.
+func tableFilter(table string) TableFilter {
+	var f TableFilter
+	for name, filter := range commandOption.TableFilters {
+		if strings.EqualFold(name, table) {
+			f = *filter
+			break
+		}
+	}
+	if f.Limit == 0 {
+		f.Limit = commandOption.Limit
+	}
+	return f
+}
+
+// filtered reports whether the records of table are filtered.
+func (f TableFilter) filtered() bool {
+	return f.Where != "" || len(f.OrderBy) > 0 || f.Limit > 0
+}
+
+// tableQuery builds the SELECT of columns of table with its filter. The
+// condition is put in parentheses and its named parameters are bound to
+// -param, the columns to order by are quoted, and the limit is written by
+// the operator.
+func tableQuery(operator DBPukeOperator, columns string, table string, placeholder func(n int) string) (string, []any, error) {
+	query := fmt.Sprintf("SELECT %s FROM %s", columns, operator.QualifiedTableName(table))
+	f := tableFilter(table)
+
+	var args []any
+	if f.Where != "" {
+		where, whereArgs, err := bindParams(f.Where, commandOption.Params, placeholder)
+		if err != nil {
+			return "", nil, err
+		}
+		query += " WHERE (" + where + ")"
+		args = whereArgs
+	}
+	if len(f.OrderBy) > 0 {
+		var order []string
+		for _, c := range f.OrderBy {
+			column := operator.QuoteIdentifier(c.Name)
+			if c.Desc {
+				column += " DESC"
+			}
+			order = append(order, column)
+		}
+		query += " ORDER BY " + strings.Join(order, ", ")
+	}
+	if f.Limit > 0 {
+		query = operator.LimitQuery(query, f.Limit)
+	}
+	return query, args, nil
+}
+
+// unusedTableFilters returns the tables of the filters which are not
+// exported, most likely misspelled.
+func unusedTableFilters(tables []string) []string {
+	var unused []string
+	for name := range commandOption.TableFilters {
+		if !containsTable(tables, name) {
+			unused = append(unused, name)
+		}
+	}
+	sort.Strings(unused)
+	return unused
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"io"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestParseOrderBy(t *testing.T) {
+	got, err := parseOrderBy("created_at DESC, id asc,name")
+	if err != nil {
+		t.Fatalf("want error: 'nil', but got '%s'", err)
+	}
+	want := []OrderColumn{{"created_at", true}, {"id", false}, {"name", false}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want: %v, but got %v", want, got)
+	}
+
+	for _, s := range []string{"id DOWN", "id, ", "id DESC NULLS"} {
+		if _, err := parseOrderBy(s); err == nil {
+			t.Errorf("want error for %s, but got nil", s)
+		}
+	}
+}
+
+func TestCheckCondition(t *testing.T) {
+	valid := []string{
+		"status = 'open'",
+		"note = 'a;b -- c' AND (price > 10 OR [weird;name] = 1)",
+		"name = 'it''s'",
+	}
+	for _, cond := range valid {
+		if err := checkCondition(cond); err != nil {
+			t.Errorf("want error: 'nil' for %s, but got '%s'", cond, err)
+		}
+	}
+
+	invalid := []string{
+		"1 = 1; DROP TABLE orders",
+		"1 = 1 --",
+		"1 = 1 /* x */",
+		"1 = 1) OR (1 = 1",
+		"(1 = 1",
+		"name = 'open",
+	}
+	for _, cond := range invalid {
+		if err := checkCondition(cond); err == nil {
+			t.Errorf("want error for %s, but got nil", cond)
+		}
+	}
+}
+
+func TestTableFilterOption(t *testing.T) {
+	if err := os.MkdirAll("testoutdir", 0755); err != nil {
+		t.Fatal(err)
+	}
+	config := "testoutdir/table-config.json"
+	if err := os.WriteFile(config, []byte(`{"tables": {"orders": {"where": "status = 'open'", "order_by": "id DESC", "limit": 10}, "users": {"limit": 5}}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	args := []string{"db-puke", "sqlite", "-d", "testdata/sqlite/test_integer_column_table.csv"}
+	option, err := parseArgs(append(args, "-table-config", config, "-where", "orders=created_at >= :since", "-table-limit", "users=3", "-limit", "100"), io.Discard)
+	if err != nil {
+		t.Fatalf("want error: 'nil', but got '%s'", err)
+	}
+	want := map[string]*TableFilter{
+		"orders": {Where: "created_at >= :since", OrderBy: []OrderColumn{{"id", true}}, Limit: 10},
+		"users":  {Limit: 3},
+	}
+	if !reflect.DeepEqual(option.TableFilters, want) {
+		t.Errorf("want: %v, but got %v", want, option.TableFilters)
+	}
+	if option.Limit != 100 {
+		t.Errorf("want: 100, but got %d", option.Limit)
+	}
+
+	invalid := [][]string{
+		{"-where", "orders"},
+		{"-where", "orders=1 = 1; DELETE FROM orders"},
+		{"-order-by", "orders=id sideways"},
+		{"-table-limit", "orders=0"},
+		{"-limit", "-1"},
+		{"-table-config", "testoutdir/missing.json"},
+		{"-limit", "10", "-q", "a=SELECT 1"},
+	}
+	for _, opts := range invalid {
+		if _, err := parseArgs(append(append([]string{}, args...), opts...), io.Discard); err == nil {
+			t.Errorf("want error for %v, but got nil", opts)
+		}
+	}
+}
+
+func TestTableQuery(t *testing.T) {
+	option := *sqliteTestOption
+	commandOption = &option
+	operator := NewSQLiteOperator()
+	question := func(n int) string { return "?" }
+
+	option.TableFilters = map[string]*TableFilter{
+		"orders": {Where: "status = :status OR note = ':status'", OrderBy: []OrderColumn{{"created_at", true}, {"id", false}}, Limit: 10},
+	}
+	option.Params = map[string]string{"status": "open"}
+	option.Limit = 5
+
+	query, args, err := tableQuery(operator, "*", "ORDERS", question)
+	if err != nil {
+		t.Fatalf("want error: 'nil', but got '%s'", err)
+	}
+	want := `SELECT * FROM "ORDERS" WHERE (status = ? OR note = ':status') ORDER BY "created_at" DESC, "id" LIMIT 10`
+	if query != want || !reflect.DeepEqual(args, []any{"open"}) {
+		t.Errorf("want: %s [open], but got %s %v", want, query, args)
+	}
+
+	query, _, _ = tableQuery(operator, "*", "users", question)
+	if want := `SELECT * FROM "users" LIMIT 5`; query != want {
+		t.Errorf("want: %s, but got %s", want, query)
+	}
+
+	if got := NewMSSqlOperator().LimitQuery("SELECT * FROM [dbo].[users] ORDER BY [id]", 5); got != "SELECT TOP (5) * FROM [dbo].[users] ORDER BY [id]" {
+		t.Errorf("want a TOP query, but got %s", got)
+	}
+}
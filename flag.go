@@ -6,25 +6,88 @@ import (
 	"io"
 	"os"
 	"strings"
+	"time"
+	"unicode/utf8"
 )
 
 var (
 	commandOption *Option
 )
 
+const (
+	InvalidOutputFormatMessage   = "error: invalid output format (-f). use csv, jsonl, parquet, sql or xlsx\n"
+	InvalidBatchSizeMessage      = "error: invalid batch size (-batch-size). specify 1 or more\n"
+	InvalidDelimiterMessage      = "error: invalid delimiter (-delimiter). use a single character, tab or pipe\n"
+	InvalidQuoteMessage          = "error: invalid quoting (-quote). use minimal, all or non-numeric\n"
+	InvalidEncodingMessage       = "error: invalid encoding (-encoding). use utf-8, utf-8-bom, shift_jis, euc-jp, utf-16, utf-16le or utf-16be\n"
+	InvalidEncodingErrorMessage  = "error: invalid encoding error policy (-encoding-error). use error, replace or html\n"
+	InvalidEncodingFormatMessage = "error: -encoding is only available for csv, jsonl and sql formats\n"
+	InvalidParallelismMessage    = "error: invalid parallelism (-j). specify 1 or more\n"
+	InvalidChunksMessage         = "error: invalid number of chunks (-chunks). specify 1 or more\n"
+	InvalidTimeoutMessage        = "error: invalid timeout (-timeout, -table-timeout). specify 0 or more, such as 30m\n"
+	InvalidResumeMessage         = "error: -resume cannot be used with -xlsx-book\n"
+	InvalidUnsupportedMessage    = "error: invalid unsupported column type policy (-unsupported). use placeholder, error, skip-column or cast\n"
+	InvalidSchemaLayoutMessage   = "error: invalid schema layout (-schema-layout). use dir or prefix\n"
+	InvalidQueryModeMessage      = "error: -q and -query-dir cannot be used with -t, -include, -exclude, -views, -chunks, table filters (-where, -order-by, -table-limit, -table-config, -limit), -unsupported other than placeholder or multiple schemas (-s)\n"
+	InvalidLimitMessage          = "error: invalid limit (-limit). specify 0 or more\n"
+	DefaultSQLBatchSize          = 100
+	DefaultParallelism           = 4
+)
+
 type Option struct {
-	DBType           string
-	Host             string
-	PortString       string
-	Port             int
-	Database         string
-	Schema           string
-	User             string
-	Password         string
-	OutDir           string
-	NullRepresent    string
-	TableNames       string
-	ParsedTableNames []string
+	DBType            string
+	Host              string
+	PortString        string
+	Port              int
+	Database          string
+	Schema            string
+	Schemas           []string
+	SchemaLayout      string
+	User              string
+	Password          string
+	SSLMode           string
+	OutDir            string
+	NullRepresent     string
+	Format            string
+	DelimiterString   string
+	Delimiter         rune
+	Quote             string
+	CRLF              bool
+	NoHeader          bool
+	Encoding          string
+	EncodingError     string
+	SummaryJSON       string
+	Parallel          int
+	SQLBatchSize      int
+	IdentityInsert    bool
+	BinaryEncoding    string
+	XLSXBook          string
+	Chunks            int
+	ChunkColumn       string
+	ChunkParts        bool
+	Consistent        bool
+	Timeout           time.Duration
+	TableTimeout      time.Duration
+	Resume            bool
+	UnsupportedPolicy string
+	Views             bool
+	QueryStrings      []string
+	QueryDir          string
+	ParamStrings      []string
+	Queries           []NamedQuery
+	Params            map[string]string
+	Wheres            []string
+	OrderBys          []string
+	TableLimits       []string
+	TableConfig       string
+	Limit             int64
+	TableFilters      map[string]*TableFilter
+	TableNames        string
+	Includes          []string
+	Excludes          []string
+	ParsedTableNames  []string
+	ParsedIncludes    []TablePattern
+	ParsedExcludes    []TablePattern
 }
 
 func rootUsageMessage() error {
@@ -36,6 +99,12 @@ Usage:
 Example:
   mssql(SQLServer):
     DB_PUKE_PASSWORD=saPassword1234 db-puke mssql -h localhost -d dummy_database -s dummy_schema -u sa
+  postgres(PostgreSQL):
+    DB_PUKE_PASSWORD=postgresPassword1234 db-puke postgres -h localhost -d dummy_database -s dummy_schema -u postgres
+  mysql(MySQL/MariaDB):
+    DB_PUKE_PASSWORD=mysqlPassword1234 db-puke mysql -h localhost -d dummy_database -u root
+  sqlite(SQLite):
+    db-puke sqlite -d ./dummy_database.sqlite3
 
 See more:
   'db-puke <database type> --help'
@@ -59,6 +128,15 @@ func parseArgs(args []string, errWriter io.Writer) (*Option, error) {
 	case DBTypeMSSql:
 		flag.ErrHelp = mssqlUsageMessage(args[0])
 		setMssqlFlag(option, fs)
+	case DBTypePostgres:
+		flag.ErrHelp = postgresUsageMessage(args[0])
+		setPostgresFlag(option, fs)
+	case DBTypeMySQL:
+		flag.ErrHelp = mysqlUsageMessage(args[0])
+		setMysqlFlag(option, fs)
+	case DBTypeSQLite:
+		flag.ErrHelp = sqliteUsageMessage(args[0])
+		setSqliteFlag(option, fs)
 	default:
 		return nil, fmt.Errorf("error: specify database type(%s) is not supported\n", option.DBType)
 	}
@@ -72,16 +150,63 @@ func parseArgs(args []string, errWriter io.Writer) (*Option, error) {
 
 	setFromEnv(option)
 
+	if err := validateCommonOption(option); err != nil {
+		return nil, err
+	}
+
 	switch option.DBType {
 	case DBTypeMSSql:
 		if err := validateMssqlOption(option); err != nil {
 			return nil, err
 		}
+	case DBTypePostgres:
+		if err := validatePostgresOption(option); err != nil {
+			return nil, err
+		}
+	case DBTypeMySQL:
+		if err := validateMysqlOption(option); err != nil {
+			return nil, err
+		}
+	case DBTypeSQLite:
+		if err := validateSqliteOption(option); err != nil {
+			return nil, err
+		}
 	default:
 		return nil, fmt.Errorf("error: specify database type(%s) is not supported\n", option.DBType)
 	}
 
-	option.ParsedTableNames = parseTableOption(option.TableNames)
+	option.Schemas = parseSchemaOption(option.Schema)
+	if len(option.Schemas) == 1 {
+		option.Schema = option.Schemas[0]
+	}
+
+	tables, err := parseTableOption(option.TableNames)
+	if err != nil {
+		return nil, err
+	}
+	option.ParsedTableNames = tables
+
+	if option.ParsedIncludes, err = parseTablePatterns(option.Includes); err != nil {
+		return nil, err
+	}
+	if option.ParsedExcludes, err = parseTablePatterns(option.Excludes); err != nil {
+		return nil, err
+	}
+
+	if option.Queries, err = parseQueryOptions(option.QueryStrings, option.QueryDir); err != nil {
+		return nil, err
+	}
+	if option.Params, err = parseParamOptions(option.ParamStrings); err != nil {
+		return nil, err
+	}
+	if option.TableFilters, err = parseTableFilters(option); err != nil {
+		return nil, err
+	}
+	if len(option.Queries) > 0 {
+		if len(option.ParsedTableNames) > 0 || len(option.Includes) > 0 || len(option.Excludes) > 0 || option.Views || option.Chunks > 1 || len(option.TableFilters) > 0 || option.Limit > 0 || option.UnsupportedPolicy != UnsupportedPolicyPlaceholder || len(option.Schemas) > 1 || option.Schema == SchemaAll {
+			return nil, fmt.Errorf(InvalidQueryModeMessage)
+		}
+	}
 
 	return option, nil
 }
@@ -89,7 +214,106 @@ func parseArgs(args []string, errWriter io.Writer) (*Option, error) {
 func setCommonFlag(option *Option, fs *flag.FlagSet) {
 	fs.StringVar(&option.OutDir, "o", "db-puke-exported", "export directory")
 	fs.StringVar(&option.NullRepresent, "N", "NULL", "string to represent NULL")
-	fs.StringVar(&option.TableNames, "t", "", "table names to export (comma-separated). exports all tables if omitted.")
+	fs.StringVar(&option.TableNames, "t", "", "table names to export (comma-separated), or @<file> listing one per line. exports all tables if omitted.")
+	fs.BoolVar(&option.Views, "views", false, "also export the views, and the synonyms of tables and views (mssql), when -t is omitted")
+	fs.Var((*stringsFlag)(&option.Includes), "include", "export only the tables matching this glob (audit_*) or re:<regexp>. can be repeated")
+	fs.Var((*stringsFlag)(&option.Excludes), "exclude", "do not export the tables matching this glob (*_bak) or re:<regexp>. can be repeated")
+	fs.Var((*stringsFlag)(&option.QueryStrings), "q", "export the result of a query instead of tables, written as <name>=<query> to <name>.csv. can be repeated")
+	fs.StringVar(&option.QueryDir, "query-dir", "", "export the result of each .sql file of this directory instead of tables, named after the file")
+	fs.Var((*stringsFlag)(&option.ParamStrings), "param", "value of the named parameter :<name> of the queries, written as <name>=<value>. can be repeated")
+	fs.Var((*stringsFlag)(&option.Wheres), "where", "export only the records of a table matching a condition, written as <table>=<condition>. can be repeated")
+	fs.Var((*stringsFlag)(&option.OrderBys), "order-by", "order the records of a table, written as <table>=<column> [ASC|DESC], .... can be repeated")
+	fs.Var((*stringsFlag)(&option.TableLimits), "table-limit", "export at most this many records of a table, written as <table>=<rows>. can be repeated")
+	fs.StringVar(&option.TableConfig, "table-config", "", "JSON file with the where, order_by and limit of tables")
+	fs.Int64Var(&option.Limit, "limit", 0, "export at most this many records of each table (0 for no limit)")
+	fs.StringVar(&option.Format, "f", OutputFormatCSV, "output format (csv, jsonl, parquet, sql, xlsx)")
+	fs.StringVar(&option.DelimiterString, "delimiter", ",", "field delimiter: a single character, tab or pipe (csv format)")
+	fs.StringVar(&option.Quote, "quote", CSVQuoteMinimal, "field quoting: minimal, all or non-numeric (csv format)")
+	fs.BoolVar(&option.CRLF, "crlf", false, "end lines with CRLF instead of LF (csv format)")
+	fs.BoolVar(&option.NoHeader, "no-header", false, "omit the header line (csv format)")
+	fs.StringVar(&option.Encoding, "encoding", EncodingUTF8, "output character encoding (utf-8, utf-8-bom, shift_jis, euc-jp, utf-16, utf-16le, utf-16be)")
+	fs.StringVar(&option.EncodingError, "encoding-error", EncodingErrorError, "handling of characters the encoding cannot represent: error, replace (with '?') or html (&#NNNN;)")
+	fs.IntVar(&option.Parallel, "j", DefaultParallelism, "number of tables exported in parallel")
+	fs.StringVar(&option.SummaryJSON, "summary-json", "", "also write the export summary as JSON to this file")
+	fs.IntVar(&option.SQLBatchSize, "batch-size", DefaultSQLBatchSize, "rows per INSERT statement (sql format)")
+	fs.StringVar(&option.XLSXBook, "xlsx-book", "", "write all tables as sheets of one workbook with this name instead of a workbook per table (xlsx format)")
+	fs.IntVar(&option.Chunks, "chunks", 1, "split each table into this many key ranges exported in parallel")
+	fs.StringVar(&option.ChunkColumn, "chunk-column", "", "integer or date/time column to split tables on (default: the first primary key column)")
+	fs.DurationVar(&option.Timeout, "timeout", 0, "cancel the export after this duration, such as 30m (0 for no limit)")
+	fs.DurationVar(&option.TableTimeout, "table-timeout", 0, "cancel the export of a table after this duration, such as 5m (0 for no limit)")
+	fs.BoolVar(&option.Resume, "resume", false, "skip the tables completed by the previous export to the same directory")
+	fs.BoolVar(&option.Consistent, "consistent", false, "read all tables in one snapshot transaction, one table at a time, so that they are consistent with each other")
+	fs.StringVar(&option.UnsupportedPolicy, "unsupported", UnsupportedPolicyPlaceholder, "handling of columns of unsupported types: placeholder (write "+UnsupportedColumnTypeOutput+"), error (fail the table), skip-column or cast (to text by the database)")
+	fs.StringVar(&option.SchemaLayout, "schema-layout", SchemaLayoutDirectory, "output of the tables of multiple schemas: dir (<schema>/<table>) or prefix (<schema>.<table>)")
+	fs.BoolVar(&option.ChunkParts, "chunk-parts", false, "write each chunk to a numbered part file instead of one file per table")
+}
+
+func validateCommonOption(option *Option) error {
+	switch option.Format {
+	case OutputFormatCSV, OutputFormatJSONL, OutputFormatParquet, OutputFormatSQL, OutputFormatXLSX:
+	default:
+		return fmt.Errorf(InvalidOutputFormatMessage)
+	}
+	if option.SQLBatchSize < 1 {
+		return fmt.Errorf(InvalidBatchSizeMessage)
+	}
+	if option.Parallel < 1 {
+		return fmt.Errorf(InvalidParallelismMessage)
+	}
+	if option.Chunks < 1 {
+		return fmt.Errorf(InvalidChunksMessage)
+	}
+	if option.Limit < 0 {
+		return fmt.Errorf(InvalidLimitMessage)
+	}
+	if option.Timeout < 0 || option.TableTimeout < 0 {
+		return fmt.Errorf(InvalidTimeoutMessage)
+	}
+	switch option.UnsupportedPolicy {
+	case UnsupportedPolicyPlaceholder, UnsupportedPolicyError, UnsupportedPolicySkipColumn, UnsupportedPolicyCast:
+	default:
+		return fmt.Errorf(InvalidUnsupportedMessage)
+	}
+	switch option.SchemaLayout {
+	case SchemaLayoutDirectory, SchemaLayoutPrefix:
+	default:
+		return fmt.Errorf(InvalidSchemaLayoutMessage)
+	}
+	if option.Resume && option.XLSXBook != "" {
+		// a shared workbook is written as a whole and cannot be continued.
+		return fmt.Errorf(InvalidResumeMessage)
+	}
+
+	delimiter, err := parseDelimiterOption(option.DelimiterString)
+	if err != nil {
+		return err
+	}
+	option.Delimiter = delimiter
+
+	switch option.Quote {
+	case CSVQuoteMinimal, CSVQuoteAll, CSVQuoteNonNumeric:
+	default:
+		return fmt.Errorf(InvalidQuoteMessage)
+	}
+
+	option.Encoding = strings.ToLower(option.Encoding)
+	if _, ok := outputEncodings[option.Encoding]; !ok && option.Encoding != EncodingUTF8 {
+		return fmt.Errorf(InvalidEncodingMessage)
+	}
+	if option.Encoding != EncodingUTF8 {
+		switch option.Format {
+		case OutputFormatParquet, OutputFormatXLSX:
+			// binary formats define their own encoding.
+			return fmt.Errorf(InvalidEncodingFormatMessage)
+		}
+	}
+	switch option.EncodingError {
+	case EncodingErrorError, EncodingErrorReplace, EncodingErrorHTML:
+	default:
+		return fmt.Errorf(InvalidEncodingErrorMessage)
+	}
+
+	return nil
 }
 
 func setFromEnv(option *Option) {
@@ -98,7 +322,30 @@ func setFromEnv(option *Option) {
 	}
 }
 
-func parseTableOption(opstr string) []string {
+func parseDelimiterOption(opstr string) (rune, error) {
+	switch opstr {
+	case "tab", `\t`:
+		return '\t', nil
+	case "pipe":
+		return '|', nil
+	}
+
+	r := []rune(opstr)
+	if len(r) != 1 || r[0] == '"' || r[0] == '\r' || r[0] == '\n' || r[0] == utf8.RuneError {
+		return 0, fmt.Errorf(InvalidDelimiterMessage)
+	}
+	return r[0], nil
+}
+
+func parseTableOption(opstr string) ([]string, error) {
+	if name, ok := strings.CutPrefix(opstr, "@"); ok {
+		tables, err := readTableFile(name)
+		if err != nil {
+			return nil, fmt.Errorf("error: cannot read the table list (-t %s). %s\n", opstr, err)
+		}
+		return tables, nil
+	}
+
 	s := strings.Trim(opstr, " ")
 	splitted := strings.Split(s, ",")
 	ret := make([]string, 0)
@@ -108,5 +355,17 @@ func parseTableOption(opstr string) []string {
 			ret = append(ret, tname)
 		}
 	}
-	return ret
+	return ret, nil
+}
+
+// stringsFlag is a flag which can be given more than once.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringsFlag) Set(s string) error {
+	*f = append(*f, s)
+	return nil
 }
@@ -2,7 +2,9 @@ package main
 
 import (
 	"io"
+	"reflect"
 	"testing"
+	"time"
 )
 
 func TestEmptyTableOption(t *testing.T) {
@@ -29,3 +31,349 @@ func TestEmptyTableOption(t *testing.T) {
 		t.Errorf("want: '', but got '%v'", option.ParsedTableNames)
 	}
 }
+
+func TestDefaultOutputFormat(t *testing.T) {
+	option, err := parseArgs([]string{
+		"db-puke",
+		"sqlite",
+		"-d",
+		"testdata/sqlite/test_integer_column_table.csv",
+	}, io.Discard)
+
+	if err != nil {
+		t.Fatalf("want error: 'nil', but got '%s'", err)
+	}
+
+	if option.Format != OutputFormatCSV {
+		t.Errorf("want: '%s', but got '%s'", OutputFormatCSV, option.Format)
+	}
+}
+
+func TestInvalidOutputFormat(t *testing.T) {
+	_, err := parseArgs([]string{
+		"db-puke",
+		"sqlite",
+		"-d",
+		"testdata/sqlite/test_integer_column_table.csv",
+		"-f",
+		"xml",
+	}, io.Discard)
+
+	if err == nil {
+		t.Fatalf("call by invalid args. want error: '%s', but got nil", InvalidOutputFormatMessage)
+	}
+
+	if err.Error() != InvalidOutputFormatMessage {
+		t.Fatalf("call by invalid args. want error: '%s', but got '%s'", InvalidOutputFormatMessage, err.Error())
+	}
+}
+
+func TestInvalidBatchSize(t *testing.T) {
+	_, err := parseArgs([]string{
+		"db-puke",
+		"sqlite",
+		"-d",
+		"testdata/sqlite/test_integer_column_table.csv",
+		"-f",
+		"sql",
+		"-batch-size",
+		"0",
+	}, io.Discard)
+
+	if err == nil {
+		t.Fatalf("call by invalid args. want error: '%s', but got nil", InvalidBatchSizeMessage)
+	}
+
+	if err.Error() != InvalidBatchSizeMessage {
+		t.Fatalf("call by invalid args. want error: '%s', but got '%s'", InvalidBatchSizeMessage, err.Error())
+	}
+}
+
+func TestDelimiterOption(t *testing.T) {
+	tests := []struct {
+		in   string
+		want rune
+	}{
+		{",", ','},
+		{"tab", '\t'},
+		{`\t`, '\t'},
+		{"\t", '\t'},
+		{"pipe", '|'},
+		{";", ';'},
+	}
+
+	for _, tt := range tests {
+		option, err := parseArgs([]string{
+			"db-puke",
+			"sqlite",
+			"-d",
+			"testdata/sqlite/test_integer_column_table.csv",
+			"-delimiter",
+			tt.in,
+		}, io.Discard)
+
+		if err != nil {
+			t.Fatalf("-delimiter %q want error: 'nil', but got '%s'", tt.in, err)
+		}
+
+		if option.Delimiter != tt.want {
+			t.Errorf("-delimiter %q want: %q, but got %q", tt.in, tt.want, option.Delimiter)
+		}
+	}
+}
+
+func TestInvalidDelimiter(t *testing.T) {
+	for _, in := range []string{"", "::", `"`, "\n"} {
+		_, err := parseArgs([]string{
+			"db-puke",
+			"sqlite",
+			"-d",
+			"testdata/sqlite/test_integer_column_table.csv",
+			"-delimiter",
+			in,
+		}, io.Discard)
+
+		if err == nil {
+			t.Fatalf("-delimiter %q want error: '%s', but got nil", in, InvalidDelimiterMessage)
+		}
+
+		if err.Error() != InvalidDelimiterMessage {
+			t.Fatalf("-delimiter %q want error: '%s', but got '%s'", in, InvalidDelimiterMessage, err.Error())
+		}
+	}
+}
+
+func TestInvalidQuote(t *testing.T) {
+	_, err := parseArgs([]string{
+		"db-puke",
+		"sqlite",
+		"-d",
+		"testdata/sqlite/test_integer_column_table.csv",
+		"-quote",
+		"none",
+	}, io.Discard)
+
+	if err == nil {
+		t.Fatalf("call by invalid args. want error: '%s', but got nil", InvalidQuoteMessage)
+	}
+
+	if err.Error() != InvalidQuoteMessage {
+		t.Fatalf("call by invalid args. want error: '%s', but got '%s'", InvalidQuoteMessage, err.Error())
+	}
+}
+
+func TestEncodingOption(t *testing.T) {
+	option, err := parseArgs([]string{
+		"db-puke",
+		"sqlite",
+		"-d",
+		"testdata/sqlite/test_integer_column_table.csv",
+		"-encoding",
+		"Shift_JIS",
+		"-encoding-error",
+		"replace",
+	}, io.Discard)
+
+	if err != nil {
+		t.Fatalf("want error: 'nil', but got '%s'", err)
+	}
+
+	if option.Encoding != EncodingShiftJIS {
+		t.Errorf("want: '%s', but got '%s'", EncodingShiftJIS, option.Encoding)
+	}
+
+	if option.EncodingError != EncodingErrorReplace {
+		t.Errorf("want: '%s', but got '%s'", EncodingErrorReplace, option.EncodingError)
+	}
+}
+
+func TestInvalidEncodingOption(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"-encoding", "latin1"}, InvalidEncodingMessage},
+		{[]string{"-encoding-error", "ignore"}, InvalidEncodingErrorMessage},
+		{[]string{"-encoding", "shift_jis", "-f", "parquet"}, InvalidEncodingFormatMessage},
+	}
+
+	for _, tt := range tests {
+		args := append([]string{
+			"db-puke",
+			"sqlite",
+			"-d",
+			"testdata/sqlite/test_integer_column_table.csv",
+		}, tt.args...)
+		_, err := parseArgs(args, io.Discard)
+
+		if err == nil {
+			t.Fatalf("%v want error: '%s', but got nil", tt.args, tt.want)
+		}
+
+		if err.Error() != tt.want {
+			t.Fatalf("%v want error: '%s', but got '%s'", tt.args, tt.want, err.Error())
+		}
+	}
+}
+
+func TestParallelismOption(t *testing.T) {
+	option, err := parseArgs([]string{
+		"db-puke",
+		"sqlite",
+		"-d",
+		"testdata/sqlite/test_integer_column_table.csv",
+	}, io.Discard)
+
+	if err != nil {
+		t.Fatalf("want error: 'nil', but got '%s'", err)
+	}
+
+	if option.Parallel != DefaultParallelism {
+		t.Errorf("want: %d, but got %d", DefaultParallelism, option.Parallel)
+	}
+
+	_, err = parseArgs([]string{
+		"db-puke",
+		"sqlite",
+		"-d",
+		"testdata/sqlite/test_integer_column_table.csv",
+		"-j",
+		"0",
+	}, io.Discard)
+
+	if err == nil {
+		t.Fatalf("call by invalid args. want error: '%s', but got nil", InvalidParallelismMessage)
+	}
+
+	if err.Error() != InvalidParallelismMessage {
+		t.Fatalf("call by invalid args. want error: '%s', but got '%s'", InvalidParallelismMessage, err.Error())
+	}
+}
+
+func TestChunksOption(t *testing.T) {
+	option, err := parseArgs([]string{
+		"db-puke",
+		"sqlite",
+		"-d",
+		"testdata/sqlite/test_integer_column_table.csv",
+	}, io.Discard)
+
+	if err != nil {
+		t.Fatalf("want error: 'nil', but got '%s'", err)
+	}
+
+	if option.Chunks != 1 {
+		t.Errorf("want: %d, but got %d", 1, option.Chunks)
+	}
+
+	_, err = parseArgs([]string{
+		"db-puke",
+		"sqlite",
+		"-d",
+		"testdata/sqlite/test_integer_column_table.csv",
+		"-chunks",
+		"0",
+	}, io.Discard)
+
+	if err == nil {
+		t.Fatalf("call by invalid args. want error: '%s', but got nil", InvalidChunksMessage)
+	}
+
+	if err.Error() != InvalidChunksMessage {
+		t.Fatalf("call by invalid args. want error: '%s', but got '%s'", InvalidChunksMessage, err.Error())
+	}
+}
+
+func TestTimeoutOption(t *testing.T) {
+	option, err := parseArgs([]string{
+		"db-puke",
+		"sqlite",
+		"-d",
+		"testdata/sqlite/test_integer_column_table.csv",
+		"-timeout",
+		"30m",
+		"-table-timeout",
+		"90s",
+	}, io.Discard)
+
+	if err != nil {
+		t.Fatalf("want error: 'nil', but got '%s'", err)
+	}
+
+	if option.Timeout != 30*time.Minute {
+		t.Errorf("want: %s, but got %s", 30*time.Minute, option.Timeout)
+	}
+	if option.TableTimeout != 90*time.Second {
+		t.Errorf("want: %s, but got %s", 90*time.Second, option.TableTimeout)
+	}
+
+	_, err = parseArgs([]string{
+		"db-puke",
+		"sqlite",
+		"-d",
+		"testdata/sqlite/test_integer_column_table.csv",
+		"-table-timeout",
+		"-1s",
+	}, io.Discard)
+
+	if err == nil {
+		t.Fatalf("call by invalid args. want error: '%s', but got nil", InvalidTimeoutMessage)
+	}
+
+	if err.Error() != InvalidTimeoutMessage {
+		t.Fatalf("call by invalid args. want error: '%s', but got '%s'", InvalidTimeoutMessage, err.Error())
+	}
+}
+
+func TestUnsupportedOption(t *testing.T) {
+	args := []string{"db-puke", "sqlite", "-d", "testdata/sqlite/test_integer_column_table.csv"}
+
+	option, err := parseArgs(args, io.Discard)
+	if err != nil {
+		t.Fatalf("want error: 'nil', but got '%s'", err)
+	}
+	if option.UnsupportedPolicy != UnsupportedPolicyPlaceholder {
+		t.Errorf("want: %s, but got %s", UnsupportedPolicyPlaceholder, option.UnsupportedPolicy)
+	}
+
+	option, err = parseArgs(append(args, "-unsupported", "skip-column"), io.Discard)
+	if err != nil {
+		t.Fatalf("want error: 'nil', but got '%s'", err)
+	}
+	if option.UnsupportedPolicy != UnsupportedPolicySkipColumn {
+		t.Errorf("want: %s, but got %s", UnsupportedPolicySkipColumn, option.UnsupportedPolicy)
+	}
+
+	_, err = parseArgs(append(args, "-unsupported", "ignore"), io.Discard)
+	if err == nil || err.Error() != InvalidUnsupportedMessage {
+		t.Errorf("want error: '%s', but got '%v'", InvalidUnsupportedMessage, err)
+	}
+}
+
+func TestSchemaOption(t *testing.T) {
+	args := []string{"db-puke", "postgres", "-h", "localhost", "-d", "dummy_database", "-u", "postgres"}
+
+	option, err := parseArgs(args, io.Discard)
+	if err != nil {
+		t.Fatalf("want error: 'nil', but got '%s'", err)
+	}
+	if option.Schema != PostgresDefaultSchema || option.SchemaLayout != SchemaLayoutDirectory {
+		t.Errorf("want: %s %s, but got %s %s", PostgresDefaultSchema, SchemaLayoutDirectory, option.Schema, option.SchemaLayout)
+	}
+
+	option, err = parseArgs(append(args, "-s", "sales, hr", "-schema-layout", "prefix"), io.Discard)
+	if err != nil {
+		t.Fatalf("want error: 'nil', but got '%s'", err)
+	}
+	if want := []string{"sales", "hr"}; !reflect.DeepEqual(option.Schemas, want) {
+		t.Errorf("want: %v, but got %v", want, option.Schemas)
+	}
+	if option.SchemaLayout != SchemaLayoutPrefix {
+		t.Errorf("want: %s, but got %s", SchemaLayoutPrefix, option.SchemaLayout)
+	}
+
+	_, err = parseArgs(append(args, "-schema-layout", "flat"), io.Discard)
+	if err == nil || err.Error() != InvalidSchemaLayoutMessage {
+		t.Errorf("want error: '%s', but got '%v'", InvalidSchemaLayoutMessage, err)
+	}
+}
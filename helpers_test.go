@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"flag"
 	"log"
 	"os"
 	"testing"
@@ -31,3 +32,31 @@ func RemoveTestOutputFile(dir string) {
 		log.Fatal("directory remove failed.", err)
 	}
 }
+
+// skipIfShort skips tests which need the database servers in compose.yml,
+// so that `go test -short` can be run offline.
+func skipIfShort(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping test against a database server in short mode")
+	}
+}
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+
+	RemoveTestOutputFile("testoutdir/sqlite")
+	createTestDatabaseSqlite()
+
+	if !testing.Short() {
+		RemoveTestOutputFile("testoutdir/mssql")
+		createTestDatabaseAndSchemaMssql()
+
+		RemoveTestOutputFile("testoutdir/postgres")
+		createTestDatabaseAndSchemaPostgres()
+
+		RemoveTestOutputFile("testoutdir/mysql")
+		createTestDatabaseMysql()
+	}
+
+	m.Run()
+}
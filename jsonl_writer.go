@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"strconv"
+)
+
+// JSONLWriter writes one JSON object per record, keyed by column name in
+// column order. Values are typed using the operator's ColumnKind.
+type JSONLWriter struct {
+	kinds  []ColumnKind
+	keys   [][]byte
+	writer *bufio.Writer
+	buf    bytes.Buffer
+}
+
+func NewJSONLWriter(operator DBPukeOperator, columnTypes []*sql.ColumnType, w io.Writer) *JSONLWriter {
+	kinds := make([]ColumnKind, len(columnTypes))
+	for i, ty := range columnTypes {
+		kinds[i] = operator.ColumnKind(ty)
+	}
+	return &JSONLWriter{
+		kinds:  kinds,
+		writer: bufio.NewWriter(w),
+	}
+}
+
+func (w *JSONLWriter) WriteHeader(columns []string) error {
+	w.keys = make([][]byte, len(columns))
+	for i, col := range columns {
+		key, err := marshalJSONString(col)
+		if err != nil {
+			return err
+		}
+		w.keys[i] = key
+	}
+	return nil
+}
+
+func (w *JSONLWriter) WriteRecord(record []string, values []any) error {
+	w.buf.Reset()
+	w.buf.WriteByte('{')
+	for i, val_str := range record {
+		if i > 0 {
+			w.buf.WriteByte(',')
+		}
+		w.buf.Write(w.keys[i])
+		w.buf.WriteByte(':')
+
+		if values[i] == nil {
+			w.buf.WriteString("null")
+			continue
+		}
+
+		v, err := w.jsonValue(val_str, w.kinds[i])
+		if err != nil {
+			return err
+		}
+		w.buf.Write(v)
+	}
+	w.buf.WriteString("}\n")
+
+	_, err := w.writer.Write(w.buf.Bytes())
+	return err
+}
+
+func (w *JSONLWriter) Flush() error {
+	return w.writer.Flush()
+}
+
+// jsonValue converts a formatted value into a JSON value. Values that do
+// not fit the kind of their column (e.g. text stored in an integer column
+// of SQLite) fall back to a JSON string.
+func (w *JSONLWriter) jsonValue(val_str string, kind ColumnKind) ([]byte, error) {
+	switch kind {
+	case ColumnKindInteger, ColumnKindFloat, ColumnKindDecimal:
+		if isNumberLiteral(val_str) {
+			return []byte(val_str), nil
+		}
+	case ColumnKindBool:
+		if b, err := strconv.ParseBool(val_str); err == nil {
+			return []byte(strconv.FormatBool(b)), nil
+		}
+	case ColumnKindJSON:
+		if json.Valid([]byte(val_str)) {
+			return []byte(val_str), nil
+		}
+	}
+	return marshalJSONString(val_str)
+}
+
+// marshalJSONString encodes s as a JSON string without escaping HTML characters.
+func marshalJSONString(s string) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(s); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
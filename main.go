@@ -1,108 +1,507 @@
 package main
 
 import (
+	"context"
 	"database/sql"
-	"encoding/csv"
 	"fmt"
 	"os"
+	"os/signal"
+	"sort"
 	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	ExitCodeSuccess      = 0
+	ExitCodeError        = 1
+	ExitCodeExportFailed = 2
 )
 
 const (
 	DBPukeVersion                 = "0.0.4"
 	DBTypeMSSql                   = "mssql"
+	DBTypePostgres                = "postgres"
+	DBTypeMySQL                   = "mysql"
+	DBTypeSQLite                  = "sqlite"
 	UnsupportedColumnTypeOutput   = "[UNSUPPORTED COLUMN TYPE]"
 	DBPukeEnvironmentNamePassword = "DB_PUKE_PASSWORD"
+	OutputFormatCSV               = "csv"
+	OutputFormatJSONL             = "jsonl"
+	OutputFormatParquet           = "parquet"
+	OutputFormatSQL               = "sql"
+	OutputFormatXLSX              = "xlsx"
+	BinaryEncoding0x              = "0x"
+	BinaryEncodingHex             = "hex"
+	BinaryEncodingBase64          = "base64"
+)
+
+// ColumnKind classifies a database column type independently of the
+// database, so that output writers can decide how to represent values.
+type ColumnKind int
+
+const (
+	ColumnKindUnsupported ColumnKind = iota
+	ColumnKindInteger
+	ColumnKindFloat
+	ColumnKindDecimal
+	ColumnKindBool
+	ColumnKindString
+	ColumnKindDate
+	ColumnKindDateTime
+	ColumnKindUUID
+	ColumnKindJSON
+	ColumnKindBinary
 )
 
 type DBPukeOperator interface {
-	DBOpen() error
+	DBOpen(ctx context.Context) error
 	DBClose() error
-	GetTableNames() ([]string, error)
-	QueryAllRecords(table string) (*sql.Rows, error)
+	GetTableNames(ctx context.Context) ([]TableObject, error)
+	QueryAllRecords(ctx context.Context, table string) (*sql.Rows, error)
+	QueryRecords(ctx context.Context, query string, params map[string]string) (*sql.Rows, error)
+	ColumnTypes(ctx context.Context, table string) ([]*sql.ColumnType, error)
+	CastToText(expr string) string
+	LimitQuery(query string, limit int64) string
 	FormatData(val any, ty *sql.ColumnType) (string, error)
+	ColumnKind(ty *sql.ColumnType) ColumnKind
+	DecimalSize(ty *sql.ColumnType) (precision, scale int64, ok bool)
+	QualifiedTableName(table string) string
+	QuoteIdentifier(name string) string
+	SQLLiteral(val any, ty *sql.ColumnType) (string, error)
+	IdentityInsert(ctx context.Context, table string) (on, off string, err error)
+	RowCountEstimates(ctx context.Context) (map[string]int64, error)
+	PrimaryKeyColumns(ctx context.Context, table string) ([]string, error)
+	ColumnRange(ctx context.Context, table string, column string) (min, max any, err error)
+	QueryRecordsInRange(ctx context.Context, table string, column string, r KeyRange) (*sql.Rows, error)
+	BeginSnapshot(ctx context.Context) error
+	EndSnapshot() error
+}
+
+// queryer is implemented by *sql.DB and *sql.Tx, so that table data can
+// be read inside the snapshot transaction of -consistent.
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
 }
 
 func main() {
 	option, err := parseArgs(os.Args, os.Stderr)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, err.Error())
-		os.Exit(1)
+		os.Exit(ExitCodeError)
 	}
 	commandOption = option
 
-	exec()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		fmt.Fprintf(os.Stderr, "Canceling the export. Interrupt again to quit at once.\n")
+		// a second signal terminates the process with the default behavior.
+		stop()
+	}()
 
-	os.Exit(0)
+	summary := exec(ctx)
+
+	if err := summary.WriteText(os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write the summary. '%s'\n", err)
+	}
+	if commandOption.SummaryJSON != "" {
+		if err := summary.WriteJSONFile(commandOption.SummaryJSON); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to write the summary file. '%s'\n", err)
+			os.Exit(ExitCodeError)
+		}
+	}
+
+	if summary.Failed() > 0 {
+		os.Exit(ExitCodeExportFailed)
+	}
+	os.Exit(ExitCodeSuccess)
 }
 
 func makeOperator() (DBPukeOperator, error) {
 	switch commandOption.DBType {
 	case DBTypeMSSql:
 		return NewMSSqlOperator(), nil
+	case DBTypePostgres:
+		return NewPostgresOperator(), nil
+	case DBTypeMySQL:
+		return NewMySQLOperator(), nil
+	case DBTypeSQLite:
+		return NewSQLiteOperator(), nil
 	default:
 		return nil, fmt.Errorf("unsupported database type: %s", commandOption.DBType)
 	}
 }
 
-func exportTableToCSV(operator DBPukeOperator, table string) error {
-	rows, err := operator.QueryAllRecords(table)
+func exportTable(ctx context.Context, operator DBPukeOperator, table string) (OutputFile, []ColumnInfo, error) {
+	rows, err := queryTable(ctx, operator, table)
 	if err != nil {
-		return err
+		return OutputFile{}, nil, err
 	}
 	defer rows.Close()
 
-	file, err := createOutputFile(table)
+	column_types, err := rows.ColumnTypes()
 	if err != nil {
-		return err
+		return OutputFile{}, nil, err
 	}
-	defer file.Close()
 
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
+	file, err := writeTableOutput(ctx, operator, table, table, rows)
+	return file, columnInfos(operator, column_types), err
+}
+
+// queryTable runs the query named table in query mode, or selects all
+// records of table.
+func queryTable(ctx context.Context, operator DBPukeOperator, table string) (*sql.Rows, error) {
+	if q, ok := findQuery(table); ok {
+		return operator.QueryRecords(ctx, q.SQL, commandOption.Params)
+	}
+	return operator.QueryAllRecords(ctx, table)
+}
+
+// writeTableOutput writes rows of table to the output file named name.
+// The rows written are counted also when it fails.
+func writeTableOutput(ctx context.Context, operator DBPukeOperator, table string, name string, rows *sql.Rows) (OutputFile, error) {
+	out, err := openOutput(name)
+	if err != nil {
+		return OutputFile{}, err
+	}
+	defer out.Abort()
+
+	count, err := writeOutput(ctx, operator, table, rows, out)
+	if err != nil {
+		return OutputFile{Rows: count}, err
+	}
+	if err := out.Commit(); err != nil {
+		return OutputFile{Rows: count}, err
+	}
+	return out.File(count), nil
+}
+
+// writeOutput writes rows of table to out and finishes it, leaving the
+// file to be put in place by Commit.
+func writeOutput(ctx context.Context, operator DBPukeOperator, table string, rows *sql.Rows, out *tableOutput) (int64, error) {
+	column_types, err := rows.ColumnTypes()
+	if err != nil {
+		return 0, err
+	}
+
+	writer, err := newOutputWriter(ctx, operator, table, column_types, out)
+	if err != nil {
+		return 0, err
+	}
 
 	err = writeOutputHeader(rows, writer)
 	if err != nil {
-		return err
+		return 0, err
+	}
+
+	count, err := writeOutputBody(ctx, operator, rows, writer)
+	if err != nil {
+		return count, err
 	}
 
-	return writeOutputBody(operator, rows, writer)
+	if err := writer.Flush(); err != nil {
+		return count, err
+	}
+	return count, out.Finish()
 }
 
-func exec() {
+// exec exports the tables and returns the result of each of them. Tables
+// not finished when ctx is canceled or -timeout is exceeded fail.
+func exec(ctx context.Context) *ExportSummary {
+	if commandOption.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, commandOption.Timeout)
+		defer cancel()
+	}
+
 	operator, err := makeOperator()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to create operator. '%s'\n", err)
-		os.Exit(1)
+		os.Exit(ExitCodeError)
+	}
+
+	var previous *Checkpoint
+	if commandOption.Resume {
+		previous, err = loadCheckpoint()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to resume. '%s'\n", err)
+			os.Exit(ExitCodeError)
+		}
+		if previous == nil {
+			fmt.Fprintf(os.Stderr, "No checkpoint found in '%s', exporting all tables.\n", commandOption.OutDir)
+		}
 	}
 
-	err = operator.DBOpen()
+	err = operator.DBOpen(ctx)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to open database. '%s'\n", err)
-		os.Exit(1)
+		os.Exit(ExitCodeError)
 	}
 	defer operator.DBClose()
 
-	tables := commandOption.ParsedTableNames
-	if len(commandOption.ParsedTableNames) == 0 {
-		all_tables, err := operator.GetTableNames()
+	if commandOption.Consistent {
+		if err := operator.BeginSnapshot(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to start a consistent snapshot. '%s'\n", err)
+			os.Exit(ExitCodeError)
+		}
+		defer operator.EndSnapshot()
+	}
+
+	tables, kinds, missing, err := exportTables(ctx, operator)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to retrieve the list of tables. '%s'\n", err)
+		os.Exit(ExitCodeError)
+	}
+
+	for _, table := range unusedTableFilters(tables) {
+		fmt.Fprintf(os.Stderr, "Warning: the filter of table '%s' matches no exported table.\n", table)
+	}
+
+	if commandOption.Format == OutputFormatXLSX && commandOption.XLSXBook != "" {
+		book, err := NewXLSXWorkbook()
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to retrieve the list of tables. '%s'\n", err)
-			os.Exit(1)
+			fmt.Fprintf(os.Stderr, "Failed to create the workbook. '%s'\n", err)
+			os.Exit(ExitCodeError)
 		}
-		tables = all_tables
+		sharedWorkbook = book
+		defer func() { sharedWorkbook = nil }()
+	}
+
+	summary := &ExportSummary{
+		StartedAt: time.Now(),
+		Results:   make([]TableResult, len(tables)),
 	}
 
+	exportCheckpoint = newCheckpoint()
+	defer func() { exportCheckpoint = nil }()
+
+	columns, unsupported := checkColumns(ctx, operator, tables)
+	printUnsupportedColumns(os.Stderr, unsupported)
+	exportColumns = columns
+	defer func() { exportColumns = nil }()
+
+	var queue []func()
+	for _, i := range scheduleTables(ctx, operator, tables) {
+		if entry, ok := previous.Completed(tables[i]); ok {
+			summary.Results[i] = entry.Result(tables[i])
+			exportCheckpoint.Keep(tables[i], entry)
+			continue
+		}
+		err := unsupportedColumnsError(tables[i])
+		if missing[tables[i]] {
+			err = fmt.Errorf("table does not exist")
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Export failed: '%s' %s\n", tables[i], err)
+			finishTable(&summary.Results[i], TableResult{Table: tables[i], Err: err})
+			continue
+		}
+		queue = append(queue, tableJobs(ctx, operator, tables[i], &summary.Results[i])...)
+	}
+	if err := exportCheckpoint.Save(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write the checkpoint. '%s'\n", err)
+	}
+	jobs := make(chan func(), len(queue))
+	for _, job := range queue {
+		jobs <- job
+	}
+	close(jobs)
+
 	wg := new(sync.WaitGroup)
-	wg.Add(len(tables))
-	for _, table := range tables {
-		go func(t string) {
+	for w := 0; w < parallelism(); w++ {
+		wg.Add(1)
+		go func() {
 			defer wg.Done()
-			err := exportTableToCSV(operator, t)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Export failed: '%s' %s\n", t, err)
+			for job := range jobs {
+				job()
 			}
-		}(table)
+		}()
 	}
 	wg.Wait()
+
+	if sharedWorkbook != nil {
+		var book OutputFile
+		err := ctx.Err()
+		for i := 0; err == nil && i < len(summary.Results); i++ {
+			if summary.Results[i].Err != nil {
+				// a failed table may have left a sheet unfinished.
+				err = sharedWorkbook.deleteSheets(tables[i])
+			}
+		}
+		if err == nil {
+			book, err = saveSharedWorkbook()
+		} else {
+			err = cancelError(ctx, err)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Export failed: '%s' %s\n", commandOption.XLSXBook, err)
+		}
+		for i := range summary.Results {
+			r := &summary.Results[i]
+			if r.Err != nil {
+				continue
+			}
+			if err != nil {
+				// none of the tables reached the disk.
+				r.Err = err
+				continue
+			}
+			// every table is a sheet of the workbook.
+			book.Rows = r.Rows
+			r.Files = []OutputFile{book}
+		}
+	}
+
+	for i := range summary.Results {
+		summary.Results[i].Kind = kinds[tables[i]]
+	}
+	summary.FinishedAt = time.Now()
+
+	if err := writeManifest(summary); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write the manifest. '%s'\n", err)
+	}
+	return summary
+}
+
+// tableJobs returns the jobs exporting table into result: one per chunk
+// with -chunks, or a single one exporting the whole table.
+func tableJobs(ctx context.Context, operator DBPukeOperator, table string, result *TableResult) []func() {
+	// the records of a filtered table are read in one query, as a limit
+	// and an order apply to the whole table.
+	if commandOption.Chunks > 1 && ctx.Err() == nil && !tableFilter(table).filtered() {
+		plan, err := planChunks(ctx, operator, table)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to split '%s' into chunks, exporting it in one query. '%s'\n", table, err)
+		}
+		if plan != nil {
+			return newChunkedExport(ctx, operator, plan, result).jobs()
+		}
+	}
+
+	return []func(){func() {
+		ctx, cancel := tableContext(ctx)
+		defer cancel()
+
+		start := time.Now()
+		file, columns, err := exportTable(ctx, operator, table)
+		if err != nil {
+			err = cancelError(ctx, err)
+			fmt.Fprintf(os.Stderr, "Export failed: '%s' %s\n", table, err)
+		}
+		finishTable(result, TableResult{
+			Table:     table,
+			Rows:      file.Rows,
+			Files:     outputFiles(file),
+			Columns:   columns,
+			StartedAt: start,
+			Duration:  time.Since(start),
+			Err:       err,
+		})
+	}}
+}
+
+// finishTable stores the result of a table and records it in the checkpoint.
+func finishTable(result *TableResult, r TableResult) {
+	*result = r
+	if err := exportCheckpoint.Record(r); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write the checkpoint. '%s'\n", err)
+	}
+}
+
+// outputFiles leaves out the files of tables of a shared workbook, which
+// have none of their own.
+func outputFiles(files ...OutputFile) []OutputFile {
+	var written []OutputFile
+	for _, f := range files {
+		if f.Name != "" {
+			written = append(written, f)
+		}
+	}
+	return written
+}
+
+// tableContext limits the export of a table to -table-timeout.
+func tableContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if commandOption.TableTimeout > 0 {
+		return context.WithTimeout(ctx, commandOption.TableTimeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// cancelError replaces the error of an export stopped by the cancellation
+// of ctx, which drivers report in their own words, with the reason.
+func cancelError(ctx context.Context, err error) error {
+	switch ctx.Err() {
+	case nil:
+		return err
+	case context.DeadlineExceeded:
+		return fmt.Errorf("timed out: %w", ctx.Err())
+	default:
+		return ctx.Err()
+	}
+}
+
+// parallelism returns the number of tables exported at the same time.
+// A snapshot transaction has a single connection, so -consistent reads
+// one table after another.
+func parallelism() int {
+	if commandOption.Consistent {
+		return 1
+	}
+	if commandOption.Parallel < 1 {
+		return 1
+	}
+	return commandOption.Parallel
+}
+
+// maxOpenConns limits the connections of the operator's *sql.DB. Each
+// worker holds one connection for its rows and the spare one serves
+// short metadata queries issued while rows are open.
+func maxOpenConns() int {
+	return parallelism() + 1
+}
+
+// scheduleTables returns the indexes of tables in the order to export
+// them, largest tables first so that a big table does not start last.
+func scheduleTables(ctx context.Context, operator DBPukeOperator, tables []string) []int {
+	order := make([]int, len(tables))
+	for i := range order {
+		order[i] = i
+	}
+	if len(tables) <= parallelism() {
+		return order
+	}
+
+	estimates, err := operator.RowCountEstimates(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to estimate the table sizes, exporting in the given order. '%s'\n", err)
+		return order
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return estimates[tables[order[a]]] > estimates[tables[order[b]]]
+	})
+	return order
+}
+
+// queryRowCountEstimates runs a query returning the schema and the name
+// of tables with their estimated row counts.
+func queryRowCountEstimates(ctx context.Context, db *sql.DB, query string, args ...any) (map[string]int64, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	estimates := make(map[string]int64)
+	for rows.Next() {
+		var schema, tname string
+		var count int64
+		if err := rows.Scan(&schema, &tname, &count); err != nil {
+			return nil, err
+		}
+		estimates[exportTableName(schema, tname)] = count
+	}
+	return estimates, rows.Err()
 }
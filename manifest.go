@@ -0,0 +1,133 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"path/filepath"
+	"time"
+)
+
+// ManifestFileName is the file in the export directory describing the
+// exported tables.
+const ManifestFileName = "manifest.json"
+
+// ColumnInfo is the database metadata of a column. Values the driver does
+// not report are left out.
+type ColumnInfo struct {
+	Name         string `json:"name"`
+	DatabaseType string `json:"database_type"`
+	Length       *int64 `json:"length,omitempty"`
+	Precision    *int64 `json:"precision,omitempty"`
+	Scale        *int64 `json:"scale,omitempty"`
+	Nullable     *bool  `json:"nullable,omitempty"`
+}
+
+func columnInfos(operator DBPukeOperator, columnTypes []*sql.ColumnType) []ColumnInfo {
+	var columns []ColumnInfo
+	for _, ty := range columnTypes {
+		c := ColumnInfo{Name: ty.Name(), DatabaseType: ty.DatabaseTypeName()}
+		if length, ok := ty.Length(); ok {
+			c.Length = &length
+		}
+		if precision, scale, ok := operator.DecimalSize(ty); ok {
+			c.Precision = &precision
+			c.Scale = &scale
+		}
+		if nullable, ok := ty.Nullable(); ok {
+			c.Nullable = &nullable
+		}
+		columns = append(columns, c)
+	}
+	return columns
+}
+
+// ManifestSource is the database the tables were exported from.
+type ManifestSource struct {
+	Type     string `json:"type"`
+	Host     string `json:"host,omitempty"`
+	Port     int    `json:"port,omitempty"`
+	Database string `json:"database"`
+	Schema   string `json:"schema,omitempty"`
+}
+
+// ManifestTable is a table of the export and its files.
+type ManifestTable struct {
+	Table      string       `json:"table"`
+	Kind       string       `json:"kind,omitempty"`
+	Status     string       `json:"status"`
+	Error      string       `json:"error,omitempty"`
+	Rows       int64        `json:"rows"`
+	StartedAt  *time.Time   `json:"started_at,omitempty"`
+	FinishedAt *time.Time   `json:"finished_at,omitempty"`
+	Files      []OutputFile `json:"files"`
+	Columns    []ColumnInfo `json:"columns"`
+}
+
+// Manifest lets consumers of an export directory check that it is
+// complete and learn the column types of the files.
+type Manifest struct {
+	DBPukeVersion string          `json:"db_puke_version"`
+	Source        ManifestSource  `json:"source"`
+	Format        string          `json:"format"`
+	Encoding      string          `json:"encoding"`
+	StartedAt     time.Time       `json:"started_at"`
+	FinishedAt    time.Time       `json:"finished_at"`
+	Complete      bool            `json:"complete"`
+	Tables        []ManifestTable `json:"tables"`
+}
+
+func newManifest(summary *ExportSummary) *Manifest {
+	m := &Manifest{
+		DBPukeVersion: DBPukeVersion,
+		Source: ManifestSource{
+			Type:     commandOption.DBType,
+			Host:     commandOption.Host,
+			Port:     commandOption.Port,
+			Database: commandOption.Database,
+			Schema:   commandOption.Schema,
+		},
+		Format:     commandOption.Format,
+		Encoding:   commandOption.Encoding,
+		StartedAt:  summary.StartedAt,
+		FinishedAt: summary.FinishedAt,
+		Complete:   summary.Failed() == 0,
+		Tables:     []ManifestTable{},
+	}
+
+	for _, r := range summary.Results {
+		t := ManifestTable{
+			Table:   r.Table,
+			Kind:    r.Kind,
+			Status:  r.Status(),
+			Rows:    r.Rows,
+			Files:   r.Files,
+			Columns: r.Columns,
+		}
+		if r.Err != nil {
+			t.Error = r.Err.Error()
+			// the rows of a failed table were not kept.
+			t.Rows = 0
+		}
+		if !r.StartedAt.IsZero() {
+			started, finished := r.StartedAt, r.StartedAt.Add(r.Duration)
+			t.StartedAt, t.FinishedAt = &started, &finished
+		}
+		if t.Files == nil {
+			t.Files = []OutputFile{}
+		}
+		if t.Columns == nil {
+			t.Columns = []ColumnInfo{}
+		}
+		m.Tables = append(m.Tables, t)
+	}
+	return m
+}
+
+// writeManifest writes the manifest of the export to the export directory.
+func writeManifest(summary *ExportSummary) error {
+	data, err := json.MarshalIndent(newManifest(summary), "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(commandOption.OutDir, ManifestFileName), append(data, '\n'))
+}
@@ -7,12 +7,15 @@ import (
 )
 
 const (
-	MssqlNoSpecifiedDatabaseMessage  = "error: please specify the database name (-d)\n"
-	MssqlNoSpecifiedSchemaMessage    = "error: please specify the schema name (-s)\n"
-	MssqlNoSpecifiedUserMessage      = "error: please specify the username (-u)\n"
-	MssqlNoSpecifiedPasswordMessage  = "error: please specify the database password (-P)\n"
-	MssqlInvalidPortSpecifiedMessage = "error: invalid port number (-p)\n"
-	MssqlDefaultPort                 = 1433
+	MssqlNoSpecifiedDatabaseMessage   = "error: please specify the database name (-d)\n"
+	MssqlNoSpecifiedSchemaMessage     = "error: please specify the schema name (-s)\n"
+	MssqlNoSpecifiedUserMessage       = "error: please specify the username (-u)\n"
+	MssqlNoSpecifiedPasswordMessage   = "error: please specify the database password (-P)\n"
+	MssqlInvalidPortSpecifiedMessage  = "error: invalid port number (-p)\n"
+	MssqlBatchSizeTooLargeMessage     = "error: batch size (-batch-size) must be 1000 or less for mssql\n"
+	MssqlInvalidBinaryEncodingMessage = "error: invalid binary encoding (-binary-encoding). use 0x, hex or base64\n"
+	MssqlDefaultPort                  = 1433
+	MssqlMaxInsertRows                = 1000
 )
 
 func mssqlUsageMessage(prg_name string) error {
@@ -34,16 +37,18 @@ func setMssqlFlag(option *Option, fs *flag.FlagSet) {
 	fs.StringVar(&option.Host, "h", "localhost", "database server host")
 	fs.StringVar(&option.PortString, "p", "", "database server port")
 	fs.StringVar(&option.Database, "d", "", "database")
-	fs.StringVar(&option.Schema, "s", "", "database schema")
+	fs.StringVar(&option.Schema, "s", "", "database schema, comma-separated schemas, or * for all schemas")
 	fs.StringVar(&option.User, "u", "", "database user name")
 	fs.StringVar(&option.Password, "P", "", "database user password(or use DB_PUKE_PASSWORD env var)")
+	fs.StringVar(&option.BinaryEncoding, "binary-encoding", BinaryEncoding0x, "text form of binary, varbinary, image and rowversion values: 0x (hex with 0x prefix), hex or base64")
+	fs.BoolVar(&option.IdentityInsert, "identity-insert", false, "wrap INSERT statements with SET IDENTITY_INSERT ON/OFF for tables with an identity column (sql format)")
 }
 
 func validateMssqlOption(option *Option) error {
 	if option.Database == "" {
 		return fmt.Errorf(MssqlNoSpecifiedDatabaseMessage)
 	}
-	if option.Schema == "" {
+	if len(parseSchemaOption(option.Schema)) == 0 {
 		return fmt.Errorf(MssqlNoSpecifiedSchemaMessage)
 	}
 	if option.User == "" {
@@ -61,6 +66,15 @@ func validateMssqlOption(option *Option) error {
 		}
 		option.Port = port
 	}
+	switch option.BinaryEncoding {
+	case BinaryEncoding0x, BinaryEncodingHex, BinaryEncodingBase64:
+	default:
+		return fmt.Errorf(MssqlInvalidBinaryEncodingMessage)
+	}
+	// SQL Server accepts at most 1000 rows in a single VALUES clause.
+	if option.SQLBatchSize > MssqlMaxInsertRows {
+		return fmt.Errorf(MssqlBatchSizeTooLargeMessage)
+	}
 
 	return nil
 }
@@ -268,3 +268,55 @@ func TestMssqlDefaultPort(t *testing.T) {
 		t.Errorf("mssql default port want: '%s', but got", MssqlNoSpecifiedPasswordMessage)
 	}
 }
+
+func TestMssqlBatchSizeTooLarge(t *testing.T) {
+	_, err := parseArgs([]string{
+		"db-puke",
+		"mssql",
+		"-d",
+		"dummy_database",
+		"-s",
+		"dummy_schema",
+		"-u",
+		"sa",
+		"-P",
+		"saPassword1234",
+		"-f",
+		"sql",
+		"-batch-size",
+		"1001",
+	}, io.Discard)
+
+	if err == nil {
+		t.Fatalf("call by invalid args. want error: '%s', but got nil", MssqlBatchSizeTooLargeMessage)
+	}
+
+	if err.Error() != MssqlBatchSizeTooLargeMessage {
+		t.Fatalf("call by invalid args. want error: '%s', but got '%s'", MssqlBatchSizeTooLargeMessage, err.Error())
+	}
+}
+
+func TestMssqlBinaryEncodingOption(t *testing.T) {
+	args := []string{"db-puke", "mssql", "-d", "dummy_database", "-s", "dummy_schema", "-u", "sa", "-P", "saPassword1234"}
+
+	option, err := parseArgs(args, io.Discard)
+	if err != nil {
+		t.Fatalf("want error: 'nil', but got '%s'", err)
+	}
+	if option.BinaryEncoding != BinaryEncoding0x {
+		t.Errorf("want default binary encoding: %s, but got %s", BinaryEncoding0x, option.BinaryEncoding)
+	}
+
+	option, err = parseArgs(append(args, "-binary-encoding", "base64"), io.Discard)
+	if err != nil {
+		t.Fatalf("want error: 'nil', but got '%s'", err)
+	}
+	if option.BinaryEncoding != BinaryEncodingBase64 {
+		t.Errorf("want binary encoding: %s, but got %s", BinaryEncodingBase64, option.BinaryEncoding)
+	}
+
+	_, err = parseArgs(append(args, "-binary-encoding", "uuencode"), io.Discard)
+	if err == nil || err.Error() != MssqlInvalidBinaryEncodingMessage {
+		t.Errorf("want error: '%s', but got '%v'", MssqlInvalidBinaryEncodingMessage, err)
+	}
+}
@@ -3,7 +3,10 @@ package main
 import (
 	"context"
 	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
+	"strings"
 	"time"
 
 	mssql "github.com/microsoft/go-mssqldb"
@@ -12,6 +15,7 @@ import (
 type MSSqlOperator struct {
 	connString string
 	db         *sql.DB
+	tx         *sql.Tx
 }
 
 func NewMSSqlOperator() *MSSqlOperator {
@@ -20,14 +24,15 @@ func NewMSSqlOperator() *MSSqlOperator {
 	return &MSSqlOperator{connString: connString}
 }
 
-func (o *MSSqlOperator) DBOpen() error {
+func (o *MSSqlOperator) DBOpen(ctx context.Context) error {
 	db, err := sql.Open("sqlserver", o.connString)
 	if err != nil {
 		return err
 	}
 	o.db = db
+	db.SetMaxOpenConns(maxOpenConns())
 
-	err = db.Ping()
+	err = db.PingContext(ctx)
 	if err != nil {
 		return err
 	}
@@ -39,45 +44,115 @@ func (o *MSSqlOperator) DBClose() error {
 	return o.db.Close()
 }
 
-func (o *MSSqlOperator) GetTableNames() ([]string, error) {
+// BeginSnapshot opens a SNAPSHOT isolation transaction, which needs
+// ALLOW_SNAPSHOT_ISOLATION to be enabled on the database.
+func (o *MSSqlOperator) BeginSnapshot(ctx context.Context) error {
+	var state int
+	query := "SELECT snapshot_isolation_state FROM sys.databases WHERE name = DB_NAME()"
+	if err := o.db.QueryRowContext(ctx, query).Scan(&state); err != nil {
+		return err
+	}
+	// 1 is ON. 2 and 3 are transitions that do not allow snapshots yet.
+	if state != 1 {
+		return fmt.Errorf("snapshot isolation is not enabled on database '%s'. enable it with ALTER DATABASE %s SET ALLOW_SNAPSHOT_ISOLATION ON",
+			commandOption.Database, o.QuoteIdentifier(commandOption.Database))
+	}
+
+	tx, err := o.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSnapshot})
+	if err != nil {
+		return err
+	}
+	o.tx = tx
+	return nil
+}
+
+// records returns where table data is read from, which is the snapshot
+// transaction while one is open.
+func (o *MSSqlOperator) records() queryer {
+	if o.tx != nil {
+		return o.tx
+	}
+	return o.db
+}
+
+func (o *MSSqlOperator) EndSnapshot() error {
+	if o.tx == nil {
+		return nil
+	}
+	err := o.tx.Commit()
+	o.tx = nil
+	return err
+}
+
+// GetTableNames returns the tables, the views and the synonyms of tables
+// and views.
+func (o *MSSqlOperator) GetTableNames(ctx context.Context) ([]TableObject, error) {
 	db := o.db
-	schema := commandOption.Schema
+	schemas, args := o.schemaCondition("TABLE_SCHEMA")
+	synonymSchemas, _ := o.schemaCondition("SCHEMA_NAME(schema_id)")
 
 	query := `
         SELECT
 			TABLE_SCHEMA,
-			TABLE_NAME 
+			TABLE_NAME,
+			TABLE_TYPE
 		FROM
 			INFORMATION_SCHEMA.TABLES 
 		WHERE
-			TABLE_TYPE = 'BASE TABLE'
+			TABLE_TYPE IN ('BASE TABLE', 'VIEW')
 		AND
-			TABLE_SCHEMA = @schema
-	`
-	rows, err := db.QueryContext(context.Background(), query, sql.Named("schema", schema))
+			` + schemas + `
+		UNION ALL
+		SELECT
+			SCHEMA_NAME(schema_id),
+			name,
+			'SYNONYM'
+		FROM
+			sys.synonyms
+		WHERE
+			(OBJECT_ID(base_object_name, 'U') IS NOT NULL OR OBJECT_ID(base_object_name, 'V') IS NOT NULL)
+		AND
+			` + synonymSchemas
+	rows, err := db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var tables []string
+	var tables []TableObject
 	for rows.Next() {
-		var schema, tname string
+		var schema, tname, tableType string
 
-		err := rows.Scan(&schema, &tname)
+		err := rows.Scan(&schema, &tname, &tableType)
 		if err != nil {
 			return nil, err
 		}
-		tables = append(tables, tname)
+		tables = append(tables, TableObject{Name: exportTableName(schema, tname), Kind: tableTypeKind(tableType)})
 	}
 	return tables, nil
 }
 
-func (o *MSSqlOperator) QueryAllRecords(table string) (*sql.Rows, error) {
-	db := o.db
-	schema := commandOption.Schema
+// schemaCondition selects the schemas of -s by column.
+func (o *MSSqlOperator) schemaCondition(column string) (string, []any) {
+	return schemaCondition(column, []string{"sys", "INFORMATION_SCHEMA"}, func(n int) string {
+		return fmt.Sprintf("@p%d", n)
+	})
+}
+
+func (o *MSSqlOperator) QueryAllRecords(ctx context.Context, table string) (*sql.Rows, error) {
+	db := o.records()
 
-	rows, err := db.Query(fmt.Sprintf("SELECT * FROM [%s].[%s]", schema, table))
+	columns, err := o.selectList(ctx, table)
+	if err != nil {
+		return nil, err
+	}
+	query, args, err := tableQuery(o, columns, table, func(n int) string {
+		return fmt.Sprintf("@p%d", n)
+	})
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -85,6 +160,131 @@ func (o *MSSqlOperator) QueryAllRecords(table string) (*sql.Rows, error) {
 	return rows, nil
 }
 
+func (o *MSSqlOperator) QueryRecords(ctx context.Context, query string, params map[string]string) (*sql.Rows, error) {
+	query, args, err := bindParams(query, params, func(n int) string {
+		return fmt.Sprintf("@p%d", n)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return o.records().QueryContext(ctx, query, args...)
+}
+
+// selectList returns the columns of table to select. The driver cannot
+// decode hierarchyid, geometry and geography values, which are converted
+// to text by the server instead, and sql_variant values are converted
+// when their base type cannot be told from the decoded value. Unsupported
+// columns are skipped or cast by -unsupported.
+//
+// The columns of a synonym are those of its base object in the same
+// database; a synonym of another database selects *. Alias types are
+// reported by their system type, and CLR types by their own name, as
+// INFORMATION_SCHEMA.COLUMNS does.
+func (o *MSSqlOperator) selectList(ctx context.Context, table string) (string, error) {
+	query := `
+		SELECT
+			c.name,
+			TYPE_NAME(CASE WHEN t.is_assembly_type = 1 THEN c.user_type_id ELSE c.system_type_id END)
+		FROM
+			sys.columns c
+		INNER JOIN
+			sys.types t ON t.user_type_id = c.user_type_id
+		WHERE
+			c.object_id = COALESCE(
+				(
+					SELECT OBJECT_ID(s.base_object_name) FROM sys.synonyms s
+					WHERE s.object_id = OBJECT_ID(@table)
+					AND PARSENAME(s.base_object_name, 4) IS NULL
+					AND COALESCE(PARSENAME(s.base_object_name, 3), DB_NAME()) = DB_NAME()
+				),
+				OBJECT_ID(@table)
+			)
+		ORDER BY
+			c.column_id
+	`
+	rows, err := o.db.QueryContext(ctx, query, sql.Named("table", o.QualifiedTableName(table)))
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	var columns []string
+	converted := false
+	for rows.Next() {
+		var name, dataType string
+		if err := rows.Scan(&name, &dataType); err != nil {
+			return "", err
+		}
+
+		quoted := o.QuoteIdentifier(name)
+		column := quoted
+		switch strings.ToLower(dataType) {
+		case "hierarchyid":
+			column = fmt.Sprintf("%s.ToString() AS %s", quoted, quoted)
+		case "geometry", "geography":
+			column = fmt.Sprintf("%s.STAsText() AS %s", quoted, quoted)
+		case "sql_variant":
+			column = fmt.Sprintf("%s AS %s", mssqlVariantText(quoted), quoted)
+		}
+
+		expr, ok := columnExpression(o, table, name, column)
+		if expr != quoted {
+			converted = true
+		}
+		if ok {
+			columns = append(columns, expr)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+
+	if !converted {
+		return "*", nil
+	}
+	return strings.Join(columns, ", "), nil
+}
+
+// mssqlVariantText converts the sql_variant values of column whose base
+// type the driver decodes into an ambiguous value (dates and times are all
+// time.Time, decimals, money and GUIDs are all []byte) into the text
+// FormatData writes for the base type. The values of other base types are
+// kept, and the result is still a sql_variant.
+func mssqlVariantText(column string) string {
+	return fmt.Sprintf(`CASE CAST(SQL_VARIANT_PROPERTY(%[1]s, 'BaseType') AS NVARCHAR(128))
+		WHEN 'date' THEN CONVERT(NVARCHAR(10), CAST(%[1]s AS DATE), 23)
+		WHEN 'datetime' THEN CONVERT(NVARCHAR(23), CAST(%[1]s AS DATETIME), 121)
+		WHEN 'smalldatetime' THEN CONVERT(NVARCHAR(19), CAST(%[1]s AS SMALLDATETIME), 120)
+		WHEN 'datetime2' THEN CONVERT(NVARCHAR(27), CAST(%[1]s AS DATETIME2(7)), 121)
+		WHEN 'time' THEN CONVERT(NVARCHAR(16), CAST(%[1]s AS TIME(7)))
+		WHEN 'datetimeoffset' THEN CONVERT(NVARCHAR(34), CAST(%[1]s AS DATETIMEOFFSET(7)), 121)
+		WHEN 'money' THEN CONVERT(NVARCHAR(30), CAST(%[1]s AS MONEY), 2)
+		WHEN 'smallmoney' THEN CONVERT(NVARCHAR(30), CAST(%[1]s AS SMALLMONEY), 2)
+		WHEN 'decimal' THEN CONVERT(NVARCHAR(50), %[1]s)
+		WHEN 'numeric' THEN CONVERT(NVARCHAR(50), %[1]s)
+		WHEN 'uniqueidentifier' THEN CONVERT(NVARCHAR(36), %[1]s)
+		ELSE %[1]s
+	END`, column)
+}
+
+// ColumnTypes returns the types of the columns as selected for the export,
+// which has the columns converted by the server as strings.
+func (o *MSSqlOperator) ColumnTypes(ctx context.Context, table string) ([]*sql.ColumnType, error) {
+	columns, err := o.selectList(ctx, table)
+	if err != nil {
+		return nil, err
+	}
+	return queryColumnTypes(ctx, o.db, fmt.Sprintf("SELECT %s FROM %s WHERE 1 = 0", columns, o.QualifiedTableName(table)))
+}
+
+func (o *MSSqlOperator) CastToText(expr string) string {
+	return fmt.Sprintf("CAST(%s AS NVARCHAR(MAX))", expr)
+}
+
+func (o *MSSqlOperator) LimitQuery(query string, limit int64) string {
+	return fmt.Sprintf("SELECT TOP (%d) %s", limit, strings.TrimPrefix(query, "SELECT "))
+}
+
 func (o *MSSqlOperator) FormatData(val any, ty *sql.ColumnType) (string, error) {
 	if val == nil {
 		return commandOption.NullRepresent, nil
@@ -134,6 +334,16 @@ func (o *MSSqlOperator) FormatData(val any, ty *sql.ColumnType) (string, error)
 	case "SMALLDATETIME":
 		t := (val).(time.Time)
 		return t.Format("2006-01-02 15:04:05"), nil
+	case "TIME":
+		t := (val).(time.Time)
+		return t.Format("15:04:05" + fractionLayout(ty)), nil
+	case "DATETIMEOFFSET":
+		t := (val).(time.Time)
+		return t.Format("2006-01-02 15:04:05" + fractionLayout(ty) + " -07:00"), nil
+	case "XML":
+		return fmt.Sprintf("%s", val), nil
+	case "SQL_VARIANT":
+		return formatVariant(val), nil
 	case "MONEY":
 		fallthrough
 	case "SMALLMONEY":
@@ -143,6 +353,13 @@ func (o *MSSqlOperator) FormatData(val any, ty *sql.ColumnType) (string, error)
 	case "DECIMAL":
 		v := val.([]uint8)
 		return string(v), nil
+	case "BINARY":
+		// rowversion (timestamp) columns are reported as binary(8).
+		fallthrough
+	case "VARBINARY":
+		fallthrough
+	case "IMAGE":
+		return encodeBinary(val.([]byte)), nil
 	case "UNIQUEIDENTIFIER":
 		byte_val := val.([]byte)
 
@@ -156,3 +373,198 @@ func (o *MSSqlOperator) FormatData(val any, ty *sql.ColumnType) (string, error)
 
 	return UnsupportedColumnTypeOutput, nil
 }
+
+// fractionLayout returns the layout of the fractional seconds of a time
+// column, which has as many digits as the scale of the column.
+func fractionLayout(ty *sql.ColumnType) string {
+	_, scale, ok := ty.DecimalSize()
+	if !ok {
+		scale = 7
+	}
+	if scale == 0 {
+		return ""
+	}
+	return "." + strings.Repeat("0", int(scale))
+}
+
+// formatVariant formats a sql_variant value by the Go type the driver
+// decoded its base type into. Base types decoded into ambiguous values
+// have already been converted to text by mssqlVariantText.
+func formatVariant(val any) string {
+	switch v := val.(type) {
+	case bool:
+		if v {
+			return "1"
+		}
+		return "0"
+	case int64:
+		return fmt.Sprintf("%d", v)
+	case float64:
+		return fmt.Sprintf("%g", v)
+	case []byte:
+		return encodeBinary(v)
+	}
+	return fmt.Sprintf("%v", val)
+}
+
+// encodeBinary returns the text form of a binary value selected by
+// -binary-encoding.
+func encodeBinary(b []byte) string {
+	switch commandOption.BinaryEncoding {
+	case BinaryEncodingHex:
+		return strings.ToUpper(hex.EncodeToString(b))
+	case BinaryEncodingBase64:
+		return base64.StdEncoding.EncodeToString(b)
+	}
+	return "0x" + strings.ToUpper(hex.EncodeToString(b))
+}
+
+func (o *MSSqlOperator) ColumnKind(ty *sql.ColumnType) ColumnKind {
+	switch ty.DatabaseTypeName() {
+	case "INT", "BIGINT", "SMALLINT", "TINYINT":
+		return ColumnKindInteger
+	case "BIT":
+		return ColumnKindBool
+	case "FLOAT", "REAL":
+		return ColumnKindFloat
+	case "MONEY", "SMALLMONEY", "NUMERIC", "DECIMAL":
+		return ColumnKindDecimal
+	case "VARCHAR", "NVARCHAR", "CHAR", "NCHAR", "TEXT", "NTEXT", "XML", "TIME", "DATETIMEOFFSET", "SQL_VARIANT":
+		return ColumnKindString
+	case "DATE":
+		return ColumnKindDate
+	case "DATETIME", "DATETIME2", "SMALLDATETIME":
+		return ColumnKindDateTime
+	case "UNIQUEIDENTIFIER":
+		return ColumnKindUUID
+	case "BINARY", "VARBINARY", "IMAGE":
+		return ColumnKindBinary
+	}
+	return ColumnKindUnsupported
+}
+
+func (o *MSSqlOperator) DecimalSize(ty *sql.ColumnType) (precision, scale int64, ok bool) {
+	// the driver reports no precision for money types, which have a fixed one.
+	switch ty.DatabaseTypeName() {
+	case "MONEY":
+		return 19, 4, true
+	case "SMALLMONEY":
+		return 10, 4, true
+	}
+	return ty.DecimalSize()
+}
+
+func (o *MSSqlOperator) RowCountEstimates(ctx context.Context) (map[string]int64, error) {
+	schemas, args := o.schemaCondition("s.name")
+	query := `
+		SELECT
+			s.name,
+			t.name,
+			SUM(p.rows)
+		FROM
+			sys.tables t
+		INNER JOIN
+			sys.schemas s ON s.schema_id = t.schema_id
+		INNER JOIN
+			sys.partitions p ON p.object_id = t.object_id AND p.index_id IN (0, 1)
+		WHERE
+			` + schemas + `
+		GROUP BY
+			s.name,
+			t.name
+	`
+	return queryRowCountEstimates(ctx, o.db, query, args...)
+}
+
+func (o *MSSqlOperator) PrimaryKeyColumns(ctx context.Context, table string) ([]string, error) {
+	query := `
+		SELECT
+			kcu.COLUMN_NAME
+		FROM
+			INFORMATION_SCHEMA.TABLE_CONSTRAINTS tc
+		INNER JOIN
+			INFORMATION_SCHEMA.KEY_COLUMN_USAGE kcu
+			ON kcu.CONSTRAINT_SCHEMA = tc.CONSTRAINT_SCHEMA
+			AND kcu.CONSTRAINT_NAME = tc.CONSTRAINT_NAME
+			AND kcu.TABLE_NAME = tc.TABLE_NAME
+		WHERE
+			tc.CONSTRAINT_TYPE = 'PRIMARY KEY'
+		AND
+			tc.TABLE_SCHEMA = @schema
+		AND
+			tc.TABLE_NAME = @table
+		ORDER BY
+			kcu.ORDINAL_POSITION
+	`
+	schema, name := splitTableName(table)
+	return queryPrimaryKeyColumns(ctx, o.db, query, sql.Named("schema", schema), sql.Named("table", name))
+}
+
+func (o *MSSqlOperator) ColumnRange(ctx context.Context, table string, column string) (min, max any, err error) {
+	return queryColumnRange(ctx, o.records(), o.QualifiedTableName(table), o.QuoteIdentifier(column))
+}
+
+func (o *MSSqlOperator) QueryRecordsInRange(ctx context.Context, table string, column string, r KeyRange) (*sql.Rows, error) {
+	columns, err := o.selectList(ctx, table)
+	if err != nil {
+		return nil, err
+	}
+	query, args := rangeQuery(columns, o.QualifiedTableName(table), o.QuoteIdentifier(column), r, func(n int) string {
+		return fmt.Sprintf("@p%d", n)
+	})
+	return o.records().QueryContext(ctx, query, args...)
+}
+
+func (o *MSSqlOperator) QualifiedTableName(table string) string {
+	schema, name := splitTableName(table)
+	return o.QuoteIdentifier(schema) + "." + o.QuoteIdentifier(name)
+}
+
+func (o *MSSqlOperator) QuoteIdentifier(name string) string {
+	return "[" + strings.ReplaceAll(name, "]", "]]") + "]"
+}
+
+func (o *MSSqlOperator) SQLLiteral(val any, ty *sql.ColumnType) (string, error) {
+	if val == nil {
+		return "NULL", nil
+	}
+	tyname := ty.DatabaseTypeName()
+
+	switch tyname {
+	case "BINARY", "VARBINARY", "IMAGE":
+		return "0x" + strings.ToUpper(hex.EncodeToString(val.([]byte))), nil
+	}
+
+	s, err := o.FormatData(val, ty)
+	if err != nil {
+		return "", err
+	}
+
+	switch o.ColumnKind(ty) {
+	case ColumnKindInteger, ColumnKindFloat, ColumnKindDecimal, ColumnKindBool:
+		return s, nil
+	case ColumnKindDate:
+		return fmt.Sprintf("CONVERT(DATE, '%s', 23)", s), nil
+	case ColumnKindDateTime:
+		// style 121 is the ODBC canonical form written by FormatData.
+		return fmt.Sprintf("CONVERT(%s, '%s', 121)", tyname, s), nil
+	case ColumnKindUUID:
+		return quoteSQLString(s), nil
+	}
+	return "N" + quoteSQLString(s), nil
+}
+
+func (o *MSSqlOperator) IdentityInsert(ctx context.Context, table string) (on, off string, err error) {
+	qualified := o.QualifiedTableName(table)
+
+	var count int
+	query := "SELECT COUNT(*) FROM sys.identity_columns WHERE object_id = OBJECT_ID(@table)"
+	if err := o.db.QueryRowContext(ctx, query, sql.Named("table", qualified)).Scan(&count); err != nil {
+		return "", "", err
+	}
+	if count == 0 {
+		return "", "", nil
+	}
+
+	return fmt.Sprintf("SET IDENTITY_INSERT %s ON;", qualified), fmt.Sprintf("SET IDENTITY_INSERT %s OFF;", qualified), nil
+}
@@ -1,12 +1,20 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
+	"os"
+	"reflect"
+	"strings"
 	"testing"
 
 	_ "github.com/microsoft/go-mssqldb"
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/reader"
+	"github.com/xuri/excelize/v2"
 )
 
 var msSqlTestOption = &Option{
@@ -15,10 +23,14 @@ var msSqlTestOption = &Option{
 	Port:          1433,
 	Database:      "dummy_database",
 	Schema:        "dummy_schema",
+	Schemas:       []string{"dummy_schema"},
 	User:          "sa",
 	Password:      "saPassword1234",
 	OutDir:        "",
 	NullRepresent: "NULL",
+	SQLBatchSize:  DefaultSQLBatchSize,
+	Encoding:      EncodingUTF8,
+	EncodingError: EncodingErrorError,
 }
 
 func execMssqlTestSQL(query string) {
@@ -52,14 +64,9 @@ func createTestDatabaseAndSchemaMssql() {
 	`)
 }
 
-func TestMain(m *testing.M) {
-	RemoveTestOutputFile("testoutdir/mssql")
-	createTestDatabaseAndSchemaMssql()
-
-	m.Run()
-}
-
 func TestMssqlIntColumn(t *testing.T) {
+	skipIfShort(t)
+
 	// Create table for test
 	execMssqlTestSQL(`
 		USE dummy_database;
@@ -78,12 +85,14 @@ func TestMssqlIntColumn(t *testing.T) {
 
 	msSqlTestOption.OutDir = "testoutdir/mssql"
 	commandOption = msSqlTestOption
-	exec()
+	exec(context.Background())
 
 	AssertCompareFiles(t, "testoutdir/mssql/test_int_column_table.csv", "testdata/mssql/test_int_column_table.csv")
 }
 
 func TestMssqlBigIntColumn(t *testing.T) {
+	skipIfShort(t)
+
 	// Create table for test
 	execMssqlTestSQL(`
 		USE dummy_database;
@@ -102,12 +111,14 @@ func TestMssqlBigIntColumn(t *testing.T) {
 
 	msSqlTestOption.OutDir = "testoutdir/mssql"
 	commandOption = msSqlTestOption
-	exec()
+	exec(context.Background())
 
 	AssertCompareFiles(t, "testoutdir/mssql/test_bigint_column_table.csv", "testdata/mssql/test_bigint_column_table.csv")
 }
 
 func TestMssqlSmallintColumn(t *testing.T) {
+	skipIfShort(t)
+
 	// Create table for test
 	execMssqlTestSQL(`
 		USE dummy_database;
@@ -126,12 +137,14 @@ func TestMssqlSmallintColumn(t *testing.T) {
 
 	msSqlTestOption.OutDir = "testoutdir/mssql"
 	commandOption = msSqlTestOption
-	exec()
+	exec(context.Background())
 
 	AssertCompareFiles(t, "testoutdir/mssql/test_smallint_column_table.csv", "testdata/mssql/test_smallint_column_table.csv")
 }
 
 func TestMssqlTinyintColumn(t *testing.T) {
+	skipIfShort(t)
+
 	// Create table for test
 	execMssqlTestSQL(`
 		USE dummy_database;
@@ -149,12 +162,14 @@ func TestMssqlTinyintColumn(t *testing.T) {
 
 	msSqlTestOption.OutDir = "testoutdir/mssql"
 	commandOption = msSqlTestOption
-	exec()
+	exec(context.Background())
 
 	AssertCompareFiles(t, "testoutdir/mssql/test_tinyint_column_table.csv", "testdata/mssql/test_tinyint_column_table.csv")
 }
 
 func TestMssqlFloatColumn(t *testing.T) {
+	skipIfShort(t)
+
 	// Create table for test
 	execMssqlTestSQL(`
 		USE dummy_database;
@@ -177,12 +192,14 @@ func TestMssqlFloatColumn(t *testing.T) {
 
 	msSqlTestOption.OutDir = "testoutdir/mssql"
 	commandOption = msSqlTestOption
-	exec()
+	exec(context.Background())
 
 	AssertCompareFiles(t, "testoutdir/mssql/test_float_column_table.csv", "testdata/mssql/test_float_column_table.csv")
 }
 
 func TestMssqlRealColumn(t *testing.T) {
+	skipIfShort(t)
+
 	// Create table for test
 	execMssqlTestSQL(`
 		USE dummy_database;
@@ -205,12 +222,14 @@ func TestMssqlRealColumn(t *testing.T) {
 
 	msSqlTestOption.OutDir = "testoutdir/mssql"
 	commandOption = msSqlTestOption
-	exec()
+	exec(context.Background())
 
 	AssertCompareFiles(t, "testoutdir/mssql/test_real_column_table.csv", "testdata/mssql/test_real_column_table.csv")
 }
 
 func TestMssqlDecimalColumn(t *testing.T) {
+	skipIfShort(t)
+
 	// Create table for test
 	execMssqlTestSQL(`
 		USE dummy_database;
@@ -229,12 +248,14 @@ func TestMssqlDecimalColumn(t *testing.T) {
 
 	msSqlTestOption.OutDir = "testoutdir/mssql"
 	commandOption = msSqlTestOption
-	exec()
+	exec(context.Background())
 
 	AssertCompareFiles(t, "testoutdir/mssql/test_decimal_column_table.csv", "testdata/mssql/test_decimal_column_table.csv")
 }
 
 func TestMssqlNumericColumn(t *testing.T) {
+	skipIfShort(t)
+
 	// Create table for test
 	execMssqlTestSQL(`
 		USE dummy_database;
@@ -253,12 +274,14 @@ func TestMssqlNumericColumn(t *testing.T) {
 
 	msSqlTestOption.OutDir = "testoutdir/mssql"
 	commandOption = msSqlTestOption
-	exec()
+	exec(context.Background())
 
 	AssertCompareFiles(t, "testoutdir/mssql/test_numeric_column_table.csv", "testdata/mssql/test_numeric_column_table.csv")
 }
 
 func TestMssqlCharColumn(t *testing.T) {
+	skipIfShort(t)
+
 	// Create table for test
 	execMssqlTestSQL(`
 		USE dummy_database;
@@ -285,12 +308,14 @@ shouldbeescape');
 
 	msSqlTestOption.OutDir = "testoutdir/mssql"
 	commandOption = msSqlTestOption
-	exec()
+	exec(context.Background())
 
 	AssertCompareFiles(t, "testoutdir/mssql/test_char_column_table.csv", "testdata/mssql/test_char_column_table.csv")
 }
 
 func TestMssqlNcharColumn(t *testing.T) {
+	skipIfShort(t)
+
 	// Create table for test
 	execMssqlTestSQL(`
 		USE dummy_database;
@@ -317,12 +342,14 @@ shouldbeescape');
 
 	msSqlTestOption.OutDir = "testoutdir/mssql"
 	commandOption = msSqlTestOption
-	exec()
+	exec(context.Background())
 
 	AssertCompareFiles(t, "testoutdir/mssql/test_nchar_column_table.csv", "testdata/mssql/test_nchar_column_table.csv")
 }
 
 func TestMssqlTextColumn(t *testing.T) {
+	skipIfShort(t)
+
 	// Create table for test
 	execMssqlTestSQL(`
 		USE dummy_database;
@@ -344,12 +371,14 @@ shouldbeescape');
 
 	msSqlTestOption.OutDir = "testoutdir/mssql"
 	commandOption = msSqlTestOption
-	exec()
+	exec(context.Background())
 
 	AssertCompareFiles(t, "testoutdir/mssql/test_text_column_table.csv", "testdata/mssql/test_text_column_table.csv")
 }
 
 func TestMssqlNtextColumn(t *testing.T) {
+	skipIfShort(t)
+
 	// Create table for test
 	execMssqlTestSQL(`
 		USE dummy_database;
@@ -371,12 +400,14 @@ shouldbeescape');
 
 	msSqlTestOption.OutDir = "testoutdir/mssql"
 	commandOption = msSqlTestOption
-	exec()
+	exec(context.Background())
 
 	AssertCompareFiles(t, "testoutdir/mssql/test_ntext_column_table.csv", "testdata/mssql/test_ntext_column_table.csv")
 }
 
 func TestMssqlVarcharColumn(t *testing.T) {
+	skipIfShort(t)
+
 	// Create table for test
 	execMssqlTestSQL(`
 		USE dummy_database;
@@ -403,12 +434,14 @@ shouldbeescape');
 
 	msSqlTestOption.OutDir = "testoutdir/mssql"
 	commandOption = msSqlTestOption
-	exec()
+	exec(context.Background())
 
 	AssertCompareFiles(t, "testoutdir/mssql/test_varchar_column_table.csv", "testdata/mssql/test_varchar_column_table.csv")
 }
 
 func TestMssqlNvarcharColumn(t *testing.T) {
+	skipIfShort(t)
+
 	// Create table for test
 	execMssqlTestSQL(`
 		USE dummy_database;
@@ -435,12 +468,14 @@ shouldbeescape');
 
 	msSqlTestOption.OutDir = "testoutdir/mssql"
 	commandOption = msSqlTestOption
-	exec()
+	exec(context.Background())
 
 	AssertCompareFiles(t, "testoutdir/mssql/test_nvarchar_column_table.csv", "testdata/mssql/test_nvarchar_column_table.csv")
 }
 
 func TestMssqlDateColumn(t *testing.T) {
+	skipIfShort(t)
+
 	// Create table for test
 	execMssqlTestSQL(`
 		USE dummy_database;
@@ -459,12 +494,14 @@ func TestMssqlDateColumn(t *testing.T) {
 
 	msSqlTestOption.OutDir = "testoutdir/mssql"
 	commandOption = msSqlTestOption
-	exec()
+	exec(context.Background())
 
 	AssertCompareFiles(t, "testoutdir/mssql/test_date_column_table.csv", "testdata/mssql/test_date_column_table.csv")
 }
 
 func TestMssqlDatetimeColumn(t *testing.T) {
+	skipIfShort(t)
+
 	// Create table for test
 	execMssqlTestSQL(`
 		USE dummy_database;
@@ -483,12 +520,14 @@ func TestMssqlDatetimeColumn(t *testing.T) {
 
 	msSqlTestOption.OutDir = "testoutdir/mssql"
 	commandOption = msSqlTestOption
-	exec()
+	exec(context.Background())
 
 	AssertCompareFiles(t, "testoutdir/mssql/test_datetime_column_table.csv", "testdata/mssql/test_datetime_column_table.csv")
 }
 
 func TestMssqlSmalldatetimeColumn(t *testing.T) {
+	skipIfShort(t)
+
 	// Create table for test
 	execMssqlTestSQL(`
 		USE dummy_database;
@@ -507,12 +546,14 @@ func TestMssqlSmalldatetimeColumn(t *testing.T) {
 
 	msSqlTestOption.OutDir = "testoutdir/mssql"
 	commandOption = msSqlTestOption
-	exec()
+	exec(context.Background())
 
 	AssertCompareFiles(t, "testoutdir/mssql/test_smalldatetime_column_table.csv", "testdata/mssql/test_smalldatetime_column_table.csv")
 }
 
 func TestMssqlDatetime2Column(t *testing.T) {
+	skipIfShort(t)
+
 	// Create table for test
 	execMssqlTestSQL(`
 		USE dummy_database;
@@ -531,12 +572,14 @@ func TestMssqlDatetime2Column(t *testing.T) {
 
 	msSqlTestOption.OutDir = "testoutdir/mssql"
 	commandOption = msSqlTestOption
-	exec()
+	exec(context.Background())
 
 	AssertCompareFiles(t, "testoutdir/mssql/test_datetime2_column_table.csv", "testdata/mssql/test_datetime2_column_table.csv")
 }
 
 func TestMssqlMoneyColumn(t *testing.T) {
+	skipIfShort(t)
+
 	// Create table for test
 	execMssqlTestSQL(`
 		USE dummy_database;
@@ -559,12 +602,14 @@ func TestMssqlMoneyColumn(t *testing.T) {
 
 	msSqlTestOption.OutDir = "testoutdir/mssql"
 	commandOption = msSqlTestOption
-	exec()
+	exec(context.Background())
 
 	AssertCompareFiles(t, "testoutdir/mssql/test_money_column_table.csv", "testdata/mssql/test_money_column_table.csv")
 }
 
 func TestMssqlSmallmoneyColumn(t *testing.T) {
+	skipIfShort(t)
+
 	// Create table for test
 	execMssqlTestSQL(`
 		USE dummy_database;
@@ -587,12 +632,14 @@ func TestMssqlSmallmoneyColumn(t *testing.T) {
 
 	msSqlTestOption.OutDir = "testoutdir/mssql"
 	commandOption = msSqlTestOption
-	exec()
+	exec(context.Background())
 
 	AssertCompareFiles(t, "testoutdir/mssql/test_smallmoney_column_table.csv", "testdata/mssql/test_smallmoney_column_table.csv")
 }
 
 func TestMssqlBitColumn(t *testing.T) {
+	skipIfShort(t)
+
 	// Create table for test
 	execMssqlTestSQL(`
 		USE dummy_database;
@@ -610,12 +657,14 @@ func TestMssqlBitColumn(t *testing.T) {
 
 	msSqlTestOption.OutDir = "testoutdir/mssql"
 	commandOption = msSqlTestOption
-	exec()
+	exec(context.Background())
 
 	AssertCompareFiles(t, "testoutdir/mssql/test_bit_column_table.csv", "testdata/mssql/test_bit_column_table.csv")
 }
 
 func TestMssqlUniqueidentifierColumn(t *testing.T) {
+	skipIfShort(t)
+
 	// Create table for test
 	execMssqlTestSQL(`
 		USE dummy_database;
@@ -633,12 +682,413 @@ func TestMssqlUniqueidentifierColumn(t *testing.T) {
 
 	msSqlTestOption.OutDir = "testoutdir/mssql"
 	commandOption = msSqlTestOption
-	exec()
+	exec(context.Background())
 
 	AssertCompareFiles(t, "testoutdir/mssql/test_uniqueidentifier_column_table.csv", "testdata/mssql/test_uniqueidentifier_column_table.csv")
 }
 
+func TestMssqlBinaryColumn(t *testing.T) {
+	skipIfShort(t)
+
+	// Create table for test
+	execMssqlTestSQL(`
+		USE dummy_database;
+		DROP TABLE IF EXISTS dummy_schema.test_binary_column_table;
+		CREATE TABLE dummy_schema.test_binary_column_table (
+			id int NOT NULL PRIMARY KEY,
+			binary_col binary(4)
+		);
+	`)
+	// Insert test data
+	execMssqlTestSQL(`
+		USE dummy_database;
+		INSERT INTO dummy_schema.test_binary_column_table (id, binary_col) VALUES (1, 0x00010203);
+		INSERT INTO dummy_schema.test_binary_column_table (id, binary_col) VALUES (2, 0xFF);
+		INSERT INTO dummy_schema.test_binary_column_table (id, binary_col) VALUES (3, NULL);
+	`)
+
+	msSqlTestOption.OutDir = "testoutdir/mssql"
+	commandOption = msSqlTestOption
+	exec(context.Background())
+
+	AssertCompareFiles(t, "testoutdir/mssql/test_binary_column_table.csv", "testdata/mssql/test_binary_column_table.csv")
+}
+
+func TestMssqlVarbinaryColumn(t *testing.T) {
+	skipIfShort(t)
+
+	// Create table for test
+	execMssqlTestSQL(`
+		USE dummy_database;
+		DROP TABLE IF EXISTS dummy_schema.test_varbinary_column_table;
+		CREATE TABLE dummy_schema.test_varbinary_column_table (
+			id int NOT NULL PRIMARY KEY,
+			varbinary_col varbinary(16)
+		);
+	`)
+	// Insert test data
+	execMssqlTestSQL(`
+		USE dummy_database;
+		INSERT INTO dummy_schema.test_varbinary_column_table (id, varbinary_col) VALUES (1, 0x);
+		INSERT INTO dummy_schema.test_varbinary_column_table (id, varbinary_col) VALUES (2, 0x0102ABCD);
+		INSERT INTO dummy_schema.test_varbinary_column_table (id, varbinary_col) VALUES (3, NULL);
+	`)
+
+	msSqlTestOption.OutDir = "testoutdir/mssql"
+	commandOption = msSqlTestOption
+	exec(context.Background())
+
+	AssertCompareFiles(t, "testoutdir/mssql/test_varbinary_column_table.csv", "testdata/mssql/test_varbinary_column_table.csv")
+}
+
+func TestMssqlVarbinaryMaxColumn(t *testing.T) {
+	skipIfShort(t)
+
+	// Create table for test
+	execMssqlTestSQL(`
+		USE dummy_database;
+		DROP TABLE IF EXISTS dummy_schema.test_varbinary_max_column_table;
+		CREATE TABLE dummy_schema.test_varbinary_max_column_table (
+			id int NOT NULL PRIMARY KEY,
+			varbinary_max_col varbinary(max)
+		);
+	`)
+	// Insert test data
+	execMssqlTestSQL(`
+		USE dummy_database;
+		INSERT INTO dummy_schema.test_varbinary_max_column_table (id, varbinary_max_col) VALUES (1, 0x0102030405060708090A);
+		INSERT INTO dummy_schema.test_varbinary_max_column_table (id, varbinary_max_col) VALUES (2, CAST(REPLICATE(CAST(0xAB AS varbinary(max)), 10000) AS varbinary(max)));
+		INSERT INTO dummy_schema.test_varbinary_max_column_table (id, varbinary_max_col) VALUES (3, NULL);
+	`)
+
+	msSqlTestOption.OutDir = "testoutdir/mssql"
+	commandOption = msSqlTestOption
+	exec(context.Background())
+
+	AssertCompareFiles(t, "testoutdir/mssql/test_varbinary_max_column_table.csv", "testdata/mssql/test_varbinary_max_column_table.csv")
+}
+
+func TestMssqlImageColumn(t *testing.T) {
+	skipIfShort(t)
+
+	// Create table for test
+	execMssqlTestSQL(`
+		USE dummy_database;
+		DROP TABLE IF EXISTS dummy_schema.test_image_column_table;
+		CREATE TABLE dummy_schema.test_image_column_table (
+			id int NOT NULL PRIMARY KEY,
+			image_col image
+		);
+	`)
+	// Insert test data
+	execMssqlTestSQL(`
+		USE dummy_database;
+		INSERT INTO dummy_schema.test_image_column_table (id, image_col) VALUES (1, 0x89504E470D0A1A0A);
+		INSERT INTO dummy_schema.test_image_column_table (id, image_col) VALUES (2, NULL);
+	`)
+
+	msSqlTestOption.OutDir = "testoutdir/mssql"
+	commandOption = msSqlTestOption
+	exec(context.Background())
+
+	AssertCompareFiles(t, "testoutdir/mssql/test_image_column_table.csv", "testdata/mssql/test_image_column_table.csv")
+}
+
+func TestMssqlRowversionColumn(t *testing.T) {
+	skipIfShort(t)
+
+	// Create table for test
+	execMssqlTestSQL(`
+		USE dummy_database;
+		DROP TABLE IF EXISTS dummy_schema.test_rowversion_column_table;
+		CREATE TABLE dummy_schema.test_rowversion_column_table (
+			id int NOT NULL PRIMARY KEY,
+			rowversion_col rowversion
+		);
+	`)
+	// Insert test data
+	execMssqlTestSQL(`
+		USE dummy_database;
+		INSERT INTO dummy_schema.test_rowversion_column_table (id) VALUES (1);
+		INSERT INTO dummy_schema.test_rowversion_column_table (id) VALUES (2);
+	`)
+
+	msSqlTestOption.OutDir = "testoutdir/mssql"
+	commandOption = msSqlTestOption
+	exec(context.Background())
+
+	// rowversion values depend on the database, so the expected output is
+	// built from the values converted to hex by the server.
+	db, err := sql.Open("sqlserver", NewMSSqlOperator().connString)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	rows, err := db.Query("SELECT id, CONVERT(varchar(18), rowversion_col, 1) FROM dummy_schema.test_rowversion_column_table ORDER BY id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	want := "id,rowversion_col\n"
+	for rows.Next() {
+		var id int
+		var version string
+		if err := rows.Scan(&id, &version); err != nil {
+			t.Fatal(err)
+		}
+		want += fmt.Sprintf("%d,%s\n", id, version)
+	}
+
+	got, err := os.ReadFile("testoutdir/mssql/test_rowversion_column_table.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("output file is not equal.\nwant:\n%s\ngot:\n%s\n", want, got)
+	}
+}
+
+func TestMssqlBinaryEncoding(t *testing.T) {
+	skipIfShort(t)
+
+	// Create table for test
+	execMssqlTestSQL(`
+		USE dummy_database;
+		DROP TABLE IF EXISTS dummy_schema.test_binary_encoding;
+		CREATE TABLE dummy_schema.test_binary_encoding (
+			id int NOT NULL PRIMARY KEY,
+			varbinary_col varbinary(16)
+		);
+	`)
+	// Insert test data
+	execMssqlTestSQL(`
+		USE dummy_database;
+		INSERT INTO dummy_schema.test_binary_encoding (id, varbinary_col) VALUES (1, 0x00FF10);
+		INSERT INTO dummy_schema.test_binary_encoding (id, varbinary_col) VALUES (2, 0x48656C6C6F);
+		INSERT INTO dummy_schema.test_binary_encoding (id, varbinary_col) VALUES (3, 0x);
+		INSERT INTO dummy_schema.test_binary_encoding (id, varbinary_col) VALUES (4, NULL);
+	`)
+
+	defer func() { msSqlTestOption.BinaryEncoding = "" }()
+	for _, encoding := range []string{BinaryEncodingHex, BinaryEncodingBase64} {
+		msSqlTestOption.OutDir = "testoutdir/mssql/binary_" + encoding
+		msSqlTestOption.BinaryEncoding = encoding
+		msSqlTestOption.ParsedTableNames = []string{"test_binary_encoding"}
+		commandOption = msSqlTestOption
+		exec(context.Background())
+		msSqlTestOption.ParsedTableNames = nil
+
+		AssertCompareFiles(t, "testoutdir/mssql/binary_"+encoding+"/test_binary_encoding.csv", "testdata/mssql/test_binary_encoding_"+encoding+".csv")
+	}
+}
+
+func TestMssqlTimeColumn(t *testing.T) {
+	skipIfShort(t)
+
+	// Create table for test
+	execMssqlTestSQL(`
+		USE dummy_database;
+		DROP TABLE IF EXISTS dummy_schema.test_time_column_table;
+		CREATE TABLE dummy_schema.test_time_column_table (
+			id int NOT NULL PRIMARY KEY,
+			time_col time(7),
+			time3_col time(3),
+			time0_col time(0)
+		);
+	`)
+	// Insert test data
+	execMssqlTestSQL(`
+		USE dummy_database;
+		INSERT INTO dummy_schema.test_time_column_table (id, time_col, time3_col, time0_col) VALUES (1, '12:34:56.1234567', '12:34:56.789', '12:34:56');
+		INSERT INTO dummy_schema.test_time_column_table (id, time_col, time3_col, time0_col) VALUES (2, '00:00:00', '00:00:00', '00:00:00');
+		INSERT INTO dummy_schema.test_time_column_table (id, time_col, time3_col, time0_col) VALUES (3, NULL, NULL, NULL);
+	`)
+
+	msSqlTestOption.OutDir = "testoutdir/mssql"
+	commandOption = msSqlTestOption
+	exec(context.Background())
+
+	AssertCompareFiles(t, "testoutdir/mssql/test_time_column_table.csv", "testdata/mssql/test_time_column_table.csv")
+}
+
+func TestMssqlDatetimeoffsetColumn(t *testing.T) {
+	skipIfShort(t)
+
+	// Create table for test
+	execMssqlTestSQL(`
+		USE dummy_database;
+		DROP TABLE IF EXISTS dummy_schema.test_datetimeoffset_column_table;
+		CREATE TABLE dummy_schema.test_datetimeoffset_column_table (
+			id int NOT NULL PRIMARY KEY,
+			datetimeoffset_col datetimeoffset(7),
+			datetimeoffset0_col datetimeoffset(0)
+		);
+	`)
+	// Insert test data
+	execMssqlTestSQL(`
+		USE dummy_database;
+		INSERT INTO dummy_schema.test_datetimeoffset_column_table (id, datetimeoffset_col, datetimeoffset0_col) VALUES (1, '2025-03-01 12:34:56.1234567 +09:00', '2025-03-01 12:34:56 +09:00');
+		INSERT INTO dummy_schema.test_datetimeoffset_column_table (id, datetimeoffset_col, datetimeoffset0_col) VALUES (2, '2025-12-31 23:59:59.5 -05:30', '2025-12-31 23:59:59 -05:30');
+		INSERT INTO dummy_schema.test_datetimeoffset_column_table (id, datetimeoffset_col, datetimeoffset0_col) VALUES (3, '2025-01-01 00:00:00 +00:00', '2025-01-01 00:00:00 +00:00');
+		INSERT INTO dummy_schema.test_datetimeoffset_column_table (id, datetimeoffset_col, datetimeoffset0_col) VALUES (4, NULL, NULL);
+	`)
+
+	msSqlTestOption.OutDir = "testoutdir/mssql"
+	commandOption = msSqlTestOption
+	exec(context.Background())
+
+	AssertCompareFiles(t, "testoutdir/mssql/test_datetimeoffset_column_table.csv", "testdata/mssql/test_datetimeoffset_column_table.csv")
+}
+
+func TestMssqlXmlColumn(t *testing.T) {
+	skipIfShort(t)
+
+	// Create table for test
+	execMssqlTestSQL(`
+		USE dummy_database;
+		DROP TABLE IF EXISTS dummy_schema.test_xml_column_table;
+		CREATE TABLE dummy_schema.test_xml_column_table (
+			id int NOT NULL PRIMARY KEY,
+			xml_col xml
+		);
+	`)
+	// Insert test data
+	execMssqlTestSQL(`
+		USE dummy_database;
+		INSERT INTO dummy_schema.test_xml_column_table (id, xml_col) VALUES (1, '<root><item>a</item><item>b &amp; c</item></root>');
+		INSERT INTO dummy_schema.test_xml_column_table (id, xml_col) VALUES (2, NULL);
+	`)
+
+	msSqlTestOption.OutDir = "testoutdir/mssql"
+	commandOption = msSqlTestOption
+	exec(context.Background())
+
+	AssertCompareFiles(t, "testoutdir/mssql/test_xml_column_table.csv", "testdata/mssql/test_xml_column_table.csv")
+}
+
+func TestMssqlSqlVariantColumn(t *testing.T) {
+	skipIfShort(t)
+
+	// Create table for test
+	execMssqlTestSQL(`
+		USE dummy_database;
+		DROP TABLE IF EXISTS dummy_schema.test_sql_variant_column_table;
+		CREATE TABLE dummy_schema.test_sql_variant_column_table (
+			id int NOT NULL PRIMARY KEY,
+			sql_variant_col sql_variant
+		);
+	`)
+	// Insert test data
+	execMssqlTestSQL(`
+		USE dummy_database;
+		INSERT INTO dummy_schema.test_sql_variant_column_table (id, sql_variant_col) VALUES (1, CAST(42 AS INT));
+		INSERT INTO dummy_schema.test_sql_variant_column_table (id, sql_variant_col) VALUES (2, CAST(3.5 AS FLOAT));
+		INSERT INTO dummy_schema.test_sql_variant_column_table (id, sql_variant_col) VALUES (3, CAST(N'text' AS NVARCHAR(10)));
+		INSERT INTO dummy_schema.test_sql_variant_column_table (id, sql_variant_col) VALUES (4, CAST(1 AS BIT));
+		INSERT INTO dummy_schema.test_sql_variant_column_table (id, sql_variant_col) VALUES (5, CAST(12.50 AS DECIMAL(10, 2)));
+		INSERT INTO dummy_schema.test_sql_variant_column_table (id, sql_variant_col) VALUES (6, CAST(12.5 AS MONEY));
+		INSERT INTO dummy_schema.test_sql_variant_column_table (id, sql_variant_col) VALUES (7, CAST('0E984725-C51C-4BF4-9960-E1C80E27ABA0' AS UNIQUEIDENTIFIER));
+		INSERT INTO dummy_schema.test_sql_variant_column_table (id, sql_variant_col) VALUES (8, CAST(0x0102 AS VARBINARY(2)));
+		INSERT INTO dummy_schema.test_sql_variant_column_table (id, sql_variant_col) VALUES (9, CAST('2025-03-01' AS DATE));
+		INSERT INTO dummy_schema.test_sql_variant_column_table (id, sql_variant_col) VALUES (10, CAST('2025-03-01 12:34:56.123' AS DATETIME));
+		INSERT INTO dummy_schema.test_sql_variant_column_table (id, sql_variant_col) VALUES (11, CAST('2025-03-01 12:34:56' AS DATETIME2(0)));
+		INSERT INTO dummy_schema.test_sql_variant_column_table (id, sql_variant_col) VALUES (12, CAST('12:34:56.1234567' AS TIME));
+		INSERT INTO dummy_schema.test_sql_variant_column_table (id, sql_variant_col) VALUES (13, CAST('2025-03-01 12:34:56.1234567 +09:00' AS DATETIMEOFFSET));
+		INSERT INTO dummy_schema.test_sql_variant_column_table (id, sql_variant_col) VALUES (14, NULL);
+	`)
+
+	msSqlTestOption.OutDir = "testoutdir/mssql"
+	commandOption = msSqlTestOption
+	exec(context.Background())
+
+	AssertCompareFiles(t, "testoutdir/mssql/test_sql_variant_column_table.csv", "testdata/mssql/test_sql_variant_column_table.csv")
+}
+
+func TestMssqlHierarchyidColumn(t *testing.T) {
+	skipIfShort(t)
+
+	// Create table for test
+	execMssqlTestSQL(`
+		USE dummy_database;
+		DROP TABLE IF EXISTS dummy_schema.test_hierarchyid_column_table;
+		CREATE TABLE dummy_schema.test_hierarchyid_column_table (
+			id int NOT NULL PRIMARY KEY,
+			hierarchyid_col hierarchyid
+		);
+	`)
+	// Insert test data
+	execMssqlTestSQL(`
+		USE dummy_database;
+		INSERT INTO dummy_schema.test_hierarchyid_column_table (id, hierarchyid_col) VALUES (1, '/');
+		INSERT INTO dummy_schema.test_hierarchyid_column_table (id, hierarchyid_col) VALUES (2, '/1/');
+		INSERT INTO dummy_schema.test_hierarchyid_column_table (id, hierarchyid_col) VALUES (3, '/1/2/');
+		INSERT INTO dummy_schema.test_hierarchyid_column_table (id, hierarchyid_col) VALUES (4, NULL);
+	`)
+
+	msSqlTestOption.OutDir = "testoutdir/mssql"
+	commandOption = msSqlTestOption
+	exec(context.Background())
+
+	AssertCompareFiles(t, "testoutdir/mssql/test_hierarchyid_column_table.csv", "testdata/mssql/test_hierarchyid_column_table.csv")
+}
+
+func TestMssqlGeometryColumn(t *testing.T) {
+	skipIfShort(t)
+
+	// Create table for test
+	execMssqlTestSQL(`
+		USE dummy_database;
+		DROP TABLE IF EXISTS dummy_schema.test_geometry_column_table;
+		CREATE TABLE dummy_schema.test_geometry_column_table (
+			id int NOT NULL PRIMARY KEY,
+			geometry_col geometry
+		);
+	`)
+	// Insert test data
+	execMssqlTestSQL(`
+		USE dummy_database;
+		INSERT INTO dummy_schema.test_geometry_column_table (id, geometry_col) VALUES (1, geometry::STGeomFromText('POINT(1 2)', 0));
+		INSERT INTO dummy_schema.test_geometry_column_table (id, geometry_col) VALUES (2, geometry::STGeomFromText('LINESTRING(0 0, 1 1)', 0));
+		INSERT INTO dummy_schema.test_geometry_column_table (id, geometry_col) VALUES (3, NULL);
+	`)
+
+	msSqlTestOption.OutDir = "testoutdir/mssql"
+	commandOption = msSqlTestOption
+	exec(context.Background())
+
+	AssertCompareFiles(t, "testoutdir/mssql/test_geometry_column_table.csv", "testdata/mssql/test_geometry_column_table.csv")
+}
+
+func TestMssqlGeographyColumn(t *testing.T) {
+	skipIfShort(t)
+
+	// Create table for test
+	execMssqlTestSQL(`
+		USE dummy_database;
+		DROP TABLE IF EXISTS dummy_schema.test_geography_column_table;
+		CREATE TABLE dummy_schema.test_geography_column_table (
+			id int NOT NULL PRIMARY KEY,
+			geography_col geography
+		);
+	`)
+	// Insert test data
+	execMssqlTestSQL(`
+		USE dummy_database;
+		INSERT INTO dummy_schema.test_geography_column_table (id, geography_col) VALUES (1, geography::STGeomFromText('POINT(139.69 35.68)', 4326));
+		INSERT INTO dummy_schema.test_geography_column_table (id, geography_col) VALUES (2, NULL);
+	`)
+
+	msSqlTestOption.OutDir = "testoutdir/mssql"
+	commandOption = msSqlTestOption
+	exec(context.Background())
+
+	AssertCompareFiles(t, "testoutdir/mssql/test_geography_column_table.csv", "testdata/mssql/test_geography_column_table.csv")
+}
+
 func TestMssqlMultipleTableOutput(t *testing.T) {
+	skipIfShort(t)
+
 	// Create table for test
 	execMssqlTestSQL(`
 		USE dummy_database;
@@ -684,7 +1134,7 @@ func TestMssqlMultipleTableOutput(t *testing.T) {
 
 	msSqlTestOption.OutDir = "testoutdir/mssql"
 	commandOption = msSqlTestOption
-	exec()
+	exec(context.Background())
 
 	AssertCompareFiles(t, "testoutdir/mssql/test_multiple_table_output1.csv", "testdata/mssql/test_multiple_table_output1.csv")
 	AssertCompareFiles(t, "testoutdir/mssql/test_multiple_table_output2.csv", "testdata/mssql/test_multiple_table_output2.csv")
@@ -694,6 +1144,8 @@ func TestMssqlMultipleTableOutput(t *testing.T) {
 }
 
 func TestMssqlMultipleColumnOutput(t *testing.T) {
+	skipIfShort(t)
+
 	// Create table for test
 	execMssqlTestSQL(`
 		USE dummy_database;
@@ -724,19 +1176,21 @@ func TestMssqlMultipleColumnOutput(t *testing.T) {
 
 	msSqlTestOption.OutDir = "testoutdir/mssql"
 	commandOption = msSqlTestOption
-	exec()
+	exec(context.Background())
 
 	AssertCompareFiles(t, "testoutdir/mssql/test_multiple_column_output.csv", "testdata/mssql/test_multiple_column_output.csv")
 }
 
 func TestMssqlUnsupportedColumnOutput(t *testing.T) {
+	skipIfShort(t)
+
 	// Create table for test
 	execMssqlTestSQL(`
 		USE dummy_database;
 		DROP TABLE IF EXISTS dummy_schema.test_unsupported_column_output;
 		CREATE TABLE dummy_schema.test_unsupported_column_output (
 			col1 int NOT NULL PRIMARY KEY,
-			unsupported_col sql_variant,
+			unsupported_col hierarchyid,
 			col2 varchar(32) NOT NULL,
 			col3 float
 		);
@@ -746,21 +1200,410 @@ func TestMssqlUnsupportedColumnOutput(t *testing.T) {
 		USE dummy_database;
 
 		INSERT INTO dummy_schema.test_unsupported_column_output (col1, unsupported_col, col2, col3)
-		VALUES (1, CAST(1 AS INT), 'test row 1', 3.14);
+		VALUES (1, '/1/', 'test row 1', 3.14);
 
 		INSERT INTO dummy_schema.test_unsupported_column_output (col1, unsupported_col, col2, col3)
-		VALUES (2, CAST(1 AS INT), 'test row 2', NULL);
+		VALUES (2, '/1/', 'test row 2', NULL);
 
 		INSERT INTO dummy_schema.test_unsupported_column_output (col1, unsupported_col, col2, col3)
-		VALUES (3, CAST(1 AS INT), '', NULL);
+		VALUES (3, '/1/', '', NULL);
 
 		INSERT INTO dummy_schema.test_unsupported_column_output (col1, unsupported_col, col2, col3)
-		VALUES (4, CAST(1 AS INT), 'TEST,STRING', 3.3);
+		VALUES (4, '/1/', 'TEST,STRING', 3.3);
 	`)
 
+	// tables have their hierarchyid columns converted to text, while a
+	// query selects them as the unsupported CLR type.
 	msSqlTestOption.OutDir = "testoutdir/mssql"
+	msSqlTestOption.Queries = []NamedQuery{{
+		Name: "test_unsupported_column_output",
+		SQL:  "SELECT col1, unsupported_col, col2, col3 FROM dummy_schema.test_unsupported_column_output ORDER BY col1",
+	}}
+	defer func() {
+		msSqlTestOption.Queries = nil
+	}()
 	commandOption = msSqlTestOption
-	exec()
+	exec(context.Background())
 
 	AssertCompareFiles(t, "testoutdir/mssql/test_unsupported_column_output.csv", "testdata/mssql/test_unsupported_column_output.csv")
 }
+
+func TestMssqlShiftJISOutput(t *testing.T) {
+	skipIfShort(t)
+
+	// Create table for test
+	execMssqlTestSQL(`
+		USE dummy_database;
+		DROP TABLE IF EXISTS dummy_schema.test_shift_jis_output;
+		CREATE TABLE dummy_schema.test_shift_jis_output (
+			col1 int NOT NULL PRIMARY KEY,
+			col2 nchar(10),
+			col3 nvarchar(30)
+		);
+	`)
+	// Insert test data
+	execMssqlTestSQL(`
+		USE dummy_database;
+		INSERT INTO dummy_schema.test_shift_jis_output (col1, col2, col3) VALUES (1, N'日本語', N'テスト,"文字列"');
+		INSERT INTO dummy_schema.test_shift_jis_output (col1, col2, col3) VALUES (2, N'ｶﾀｶﾅ', N'絵文字😀');
+		INSERT INTO dummy_schema.test_shift_jis_output (col1, col2, col3) VALUES (3, NULL, N'');
+	`)
+
+	msSqlTestOption.OutDir = "testoutdir/mssql"
+	msSqlTestOption.Encoding = EncodingShiftJIS
+	msSqlTestOption.EncodingError = EncodingErrorReplace
+	defer func() {
+		msSqlTestOption.Encoding = EncodingUTF8
+		msSqlTestOption.EncodingError = EncodingErrorError
+	}()
+	commandOption = msSqlTestOption
+	exec(context.Background())
+
+	AssertCompareFiles(t, "testoutdir/mssql/test_shift_jis_output.csv", "testdata/mssql/test_shift_jis_output.csv")
+}
+
+func TestMssqlUTF8BOMOutput(t *testing.T) {
+	skipIfShort(t)
+
+	// Create table for test
+	execMssqlTestSQL(`
+		USE dummy_database;
+		DROP TABLE IF EXISTS dummy_schema.test_utf8_bom_output;
+		CREATE TABLE dummy_schema.test_utf8_bom_output (
+			col1 int NOT NULL PRIMARY KEY,
+			col2 nchar(10),
+			col3 nvarchar(30)
+		);
+	`)
+	// Insert test data
+	execMssqlTestSQL(`
+		USE dummy_database;
+		INSERT INTO dummy_schema.test_utf8_bom_output (col1, col2, col3) VALUES (1, N'日本語', N'テスト,"文字列"');
+		INSERT INTO dummy_schema.test_utf8_bom_output (col1, col2, col3) VALUES (2, N'ｶﾀｶﾅ', N'絵文字😀');
+	`)
+
+	msSqlTestOption.OutDir = "testoutdir/mssql"
+	msSqlTestOption.Encoding = EncodingUTF8BOM
+	defer func() { msSqlTestOption.Encoding = EncodingUTF8 }()
+	commandOption = msSqlTestOption
+	exec(context.Background())
+
+	AssertCompareFiles(t, "testoutdir/mssql/test_utf8_bom_output.csv", "testdata/mssql/test_utf8_bom_output.csv")
+}
+
+func TestMssqlJSONLOutput(t *testing.T) {
+	skipIfShort(t)
+
+	// Create table for test
+	execMssqlTestSQL(`
+		USE dummy_database;
+		DROP TABLE IF EXISTS dummy_schema.test_jsonl_output;
+		CREATE TABLE dummy_schema.test_jsonl_output (
+			col1 int NOT NULL PRIMARY KEY,
+			col2 nvarchar(32),
+			col3 float,
+			col4 bit,
+			col5 decimal(15, 3),
+			col6 datetime2
+		);
+	`)
+	// Insert test data
+	execMssqlTestSQL(`
+		USE dummy_database;
+
+		INSERT INTO dummy_schema.test_jsonl_output (col1, col2, col3, col4, col5, col6)
+		VALUES (1, N'test row 1', 3.14, 0, 999999999999.999, '2025-03-22 21:54:24.1234567');
+
+		INSERT INTO dummy_schema.test_jsonl_output (col1, col2, col3, col4, col5, col6)
+		VALUES (2, N'テスト,"STRING"', NULL, 1, -1, NULL);
+
+		INSERT INTO dummy_schema.test_jsonl_output (col1, col2, col3, col4, col5, col6)
+		VALUES (3, NULL, -2.23E-308, NULL, NULL, NULL);
+	`)
+
+	msSqlTestOption.OutDir = "testoutdir/mssql"
+	msSqlTestOption.Format = OutputFormatJSONL
+	defer func() { msSqlTestOption.Format = OutputFormatCSV }()
+	commandOption = msSqlTestOption
+	exec(context.Background())
+
+	AssertCompareFiles(t, "testoutdir/mssql/test_jsonl_output.jsonl", "testdata/mssql/test_jsonl_output.jsonl")
+}
+
+func TestMssqlSQLOutput(t *testing.T) {
+	skipIfShort(t)
+
+	// Create table for test
+	execMssqlTestSQL(`
+		USE dummy_database;
+		DROP TABLE IF EXISTS dummy_schema.test_sql_output;
+		CREATE TABLE dummy_schema.test_sql_output (
+			col1 int IDENTITY(1, 1) NOT NULL PRIMARY KEY,
+			col2 nvarchar(32),
+			col3 float,
+			col4 bit,
+			col5 date,
+			col6 datetime2,
+			col7 varbinary(8)
+		);
+	`)
+	// Insert test data
+	execMssqlTestSQL(`
+		USE dummy_database;
+
+		INSERT INTO dummy_schema.test_sql_output (col2, col3, col4, col5, col6, col7)
+		VALUES (N'test row 1', 3.14, 1, '2025-03-22', '2025-03-22 21:54:24.1234567', 0xDEADBEEF);
+
+		INSERT INTO dummy_schema.test_sql_output (col2, col3, col4, col5, col6, col7)
+		VALUES (N'it''s テスト', NULL, 0, NULL, NULL, NULL);
+
+		INSERT INTO dummy_schema.test_sql_output (col2, col3, col4, col5, col6, col7)
+		VALUES (NULL, NULL, NULL, NULL, NULL, NULL);
+	`)
+
+	msSqlTestOption.OutDir = "testoutdir/mssql"
+	msSqlTestOption.Format = OutputFormatSQL
+	msSqlTestOption.SQLBatchSize = 2
+	msSqlTestOption.IdentityInsert = true
+	defer func() {
+		msSqlTestOption.Format = OutputFormatCSV
+		msSqlTestOption.SQLBatchSize = DefaultSQLBatchSize
+		msSqlTestOption.IdentityInsert = false
+	}()
+	commandOption = msSqlTestOption
+	exec(context.Background())
+
+	AssertCompareFiles(t, "testoutdir/mssql/test_sql_output.sql", "testdata/mssql/test_sql_output.sql")
+}
+
+func TestMssqlXLSXOutput(t *testing.T) {
+	skipIfShort(t)
+
+	// Create table for test
+	execMssqlTestSQL(`
+		USE dummy_database;
+		DROP TABLE IF EXISTS dummy_schema.test_xlsx_output;
+		CREATE TABLE dummy_schema.test_xlsx_output (
+			col1 int NOT NULL PRIMARY KEY,
+			col2 nvarchar(32),
+			col3 bit,
+			col4 decimal(20, 3),
+			col5 date
+		);
+	`)
+	// Insert test data
+	execMssqlTestSQL(`
+		USE dummy_database;
+
+		INSERT INTO dummy_schema.test_xlsx_output (col1, col2, col3, col4, col5)
+		VALUES (1, N'00123', 1, 12345.678, '2025-03-22');
+
+		INSERT INTO dummy_schema.test_xlsx_output (col1, col2, col3, col4, col5)
+		VALUES (2, N'テスト', 0, 12345678901234567.123, NULL);
+	`)
+
+	msSqlTestOption.OutDir = "testoutdir/mssql"
+	msSqlTestOption.Format = OutputFormatXLSX
+	defer func() { msSqlTestOption.Format = OutputFormatCSV }()
+	commandOption = msSqlTestOption
+	exec(context.Background())
+
+	f, err := excelize.OpenFile("testoutdir/mssql/test_xlsx_output.xlsx")
+	if err != nil {
+		t.Fatalf("open xlsx file failed: %v", err)
+	}
+	defer f.Close()
+
+	rows, err := f.GetRows("test_xlsx_output")
+	if err != nil {
+		t.Fatalf("get rows failed: %v", err)
+	}
+	want := [][]string{
+		{"col1", "col2", "col3", "col4", "col5"},
+		{"1", "00123", "TRUE", "12345.678", "2025-03-22"},
+		{"2", "テスト", "FALSE", "12345678901234567.123"},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("want: %v, but got %v", want, rows)
+	}
+
+	// decimals beyond Excel's 15 digits are kept as text
+	ty, err := f.GetCellType("test_xlsx_output", "D3")
+	if err != nil {
+		t.Fatalf("get cell type failed: %v", err)
+	}
+	if ty != excelize.CellTypeInlineString {
+		t.Errorf("want: %v, but got %v", excelize.CellTypeInlineString, ty)
+	}
+}
+
+func TestMssqlParquetOutput(t *testing.T) {
+	skipIfShort(t)
+
+	// Create table for test
+	execMssqlTestSQL(`
+		USE dummy_database;
+		DROP TABLE IF EXISTS dummy_schema.test_parquet_output;
+		CREATE TABLE dummy_schema.test_parquet_output (
+			col1 int NOT NULL PRIMARY KEY,
+			col2 decimal(15, 3),
+			col3 money,
+			col4 uniqueidentifier,
+			col5 datetime2,
+			col6 datetime,
+			col7 datetimeoffset(3)
+		);
+	`)
+	// Insert test data
+	execMssqlTestSQL(`
+		USE dummy_database;
+
+		INSERT INTO dummy_schema.test_parquet_output (col1, col2, col3, col4, col5, col6, col7)
+		VALUES (1, 999999999999.999, -922337203685477.5808, '0E984725-C51C-4BF4-9960-E1C80E27ABA0', '2025-03-22 21:54:24.1234567', '2025-03-22 21:54:24.123', '2025-03-22 21:54:24.123 +09:00');
+
+		INSERT INTO dummy_schema.test_parquet_output (col1, col2, col3, col4, col5, col6, col7)
+		VALUES (2, NULL, NULL, NULL, NULL, NULL, NULL);
+	`)
+
+	msSqlTestOption.OutDir = "testoutdir/mssql"
+	msSqlTestOption.Format = OutputFormatParquet
+	defer func() { msSqlTestOption.Format = OutputFormatCSV }()
+	commandOption = msSqlTestOption
+	exec(context.Background())
+
+	fr, err := local.NewLocalFileReader("testoutdir/mssql/test_parquet_output.parquet")
+	if err != nil {
+		t.Fatalf("open parquet file failed: %v", err)
+	}
+	defer fr.Close()
+
+	pr, err := reader.NewParquetColumnReader(fr, 1)
+	if err != nil {
+		t.Fatalf("read parquet file failed: %v", err)
+	}
+	defer pr.ReadStop()
+
+	elements := pr.SchemaHandler.SchemaElements[1:]
+	if elements[0].GetRepetitionType() != parquet.FieldRepetitionType_REQUIRED {
+		t.Errorf("col1 repetition want: REQUIRED, but got %v", elements[0].GetRepetitionType())
+	}
+	if elements[1].GetType() != parquet.Type_INT64 || elements[1].GetPrecision() != 15 || elements[1].GetScale() != 3 {
+		t.Errorf("col2 want: INT64 DECIMAL(15, 3), but got %v DECIMAL(%d, %d)", elements[1].GetType(), elements[1].GetPrecision(), elements[1].GetScale())
+	}
+	if elements[2].GetType() != parquet.Type_BYTE_ARRAY || elements[2].GetPrecision() != 19 || elements[2].GetScale() != 4 {
+		t.Errorf("col3 want: BYTE_ARRAY DECIMAL(19, 4), but got %v DECIMAL(%d, %d)", elements[2].GetType(), elements[2].GetPrecision(), elements[2].GetScale())
+	}
+	if !elements[3].GetLogicalType().IsSetUUID() {
+		t.Errorf("col4 want: UUID, but got %v", elements[3].GetLogicalType())
+	}
+	if !elements[4].GetLogicalType().GetTIMESTAMP().GetUnit().IsSetNANOS() {
+		t.Errorf("col5 timestamp unit want: NANOS, but got %v", elements[4].GetLogicalType())
+	}
+	if !elements[5].GetLogicalType().GetTIMESTAMP().GetUnit().IsSetMICROS() {
+		t.Errorf("col6 timestamp unit want: MICROS, but got %v", elements[5].GetLogicalType())
+	}
+	if ts := elements[6].GetLogicalType().GetTIMESTAMP(); ts == nil || !ts.IsAdjustedToUTC || !ts.GetUnit().IsSetMILLIS() {
+		t.Errorf("col7 want: TIMESTAMP(MILLIS) adjusted to UTC, but got %v", elements[6].GetLogicalType())
+	}
+	if ts := elements[4].GetLogicalType().GetTIMESTAMP(); ts.IsAdjustedToUTC {
+		t.Errorf("col5 want: TIMESTAMP not adjusted to UTC, but got %v", elements[4].GetLogicalType())
+	}
+
+	wantValues := [][]interface{}{
+		{int64(1), int64(2)},
+		{int64(999999999999999), nil},
+		{"\x80\x00\x00\x00\x00\x00\x00\x00", nil},
+		{"\x0e\x98\x47\x25\xc5\x1c\x4b\xf4\x99\x60\xe1\xc8\x0e\x27\xab\xa0", nil},
+		{int64(1742680464123456700), nil},
+		{int64(1742680464123000), nil},
+		{int64(1742648064123), nil},
+	}
+
+	for i, want := range wantValues {
+		got, _, _, err := pr.ReadColumnByIndex(int64(i), 2)
+		if err != nil {
+			t.Fatalf("read column %d failed: %v", i, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("column %d want: %v, but got %v", i, want, got)
+		}
+	}
+}
+
+func TestMssqlConsistentSnapshot(t *testing.T) {
+	skipIfShort(t)
+
+	commandOption = msSqlTestOption
+	operator := NewMSSqlOperator()
+	if err := operator.DBOpen(context.Background()); err != nil {
+		t.Fatalf("open database failed: %v", err)
+	}
+	defer operator.DBClose()
+
+	execMssqlTestSQL("ALTER DATABASE dummy_database SET ALLOW_SNAPSHOT_ISOLATION OFF;")
+	err := operator.BeginSnapshot(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "snapshot isolation is not enabled") {
+		t.Errorf("want error: snapshot isolation is not enabled, but got '%v'", err)
+	}
+
+	execMssqlTestSQL("ALTER DATABASE dummy_database SET ALLOW_SNAPSHOT_ISOLATION ON;")
+	defer execMssqlTestSQL("ALTER DATABASE dummy_database SET ALLOW_SNAPSHOT_ISOLATION OFF;")
+	if err := operator.BeginSnapshot(context.Background()); err != nil {
+		t.Fatalf("want error: 'nil', but got '%s'", err)
+	}
+	if err := operator.EndSnapshot(); err != nil {
+		t.Errorf("want error: 'nil', but got '%s'", err)
+	}
+}
+
+func TestMssqlSynonymColumns(t *testing.T) {
+	skipIfShort(t)
+
+	// Create table for test
+	execMssqlTestSQL(`
+		USE dummy_database;
+		DROP SYNONYM IF EXISTS dummy_schema.test_synonym_columns;
+		DROP TABLE IF EXISTS dummy_schema.test_synonym_base_table;
+		CREATE TABLE dummy_schema.test_synonym_base_table (
+			id int NOT NULL PRIMARY KEY,
+			hierarchyid_col hierarchyid,
+			geometry_col geometry
+		);
+		CREATE SYNONYM dummy_schema.test_synonym_columns FOR dummy_schema.test_synonym_base_table;
+	`)
+	// Insert test data
+	execMssqlTestSQL(`
+		USE dummy_database;
+		INSERT INTO dummy_schema.test_synonym_base_table (id, hierarchyid_col, geometry_col) VALUES (1, '/1/', geometry::STGeomFromText('POINT (1 2)', 0));
+		INSERT INTO dummy_schema.test_synonym_base_table (id, hierarchyid_col, geometry_col) VALUES (2, NULL, NULL);
+	`)
+
+	msSqlTestOption.OutDir = "testoutdir/mssql/synonym"
+	msSqlTestOption.ParsedTableNames = []string{"test_synonym_columns"}
+	defer func() {
+		msSqlTestOption.ParsedTableNames = nil
+	}()
+	commandOption = msSqlTestOption
+
+	// exec only warns when the tables cannot be listed, so the kind of the
+	// synonym is checked on the list itself first.
+	operator := NewMSSqlOperator()
+	if err := operator.DBOpen(context.Background()); err != nil {
+		t.Fatalf("open database failed: %v", err)
+	}
+	objects, err := operator.GetTableNames(context.Background())
+	operator.DBClose()
+	if err != nil {
+		t.Fatalf("get table names failed: %v", err)
+	}
+	if object, ok := findTable(objects, "test_synonym_columns"); !ok || object.Kind != ObjectKindSynonym {
+		t.Fatalf("want the synonym listed, but got %+v", object)
+	}
+
+	summary := exec(context.Background())
+
+	if r := summary.Results[0]; r.Err != nil || r.Kind != ObjectKindSynonym {
+		t.Errorf("want the synonym exported, but got %+v", r)
+	}
+	AssertCompareFiles(t, "testoutdir/mssql/synonym/test_synonym_columns.csv", "testdata/mssql/test_synonym_columns.csv")
+}
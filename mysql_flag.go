@@ -0,0 +1,57 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+)
+
+const (
+	MysqlNoSpecifiedDatabaseMessage  = "error: please specify the database name (-d)\n"
+	MysqlNoSpecifiedUserMessage      = "error: please specify the username (-u)\n"
+	MysqlInvalidPortSpecifiedMessage = "error: invalid port number (-p)\n"
+	MysqlDefaultPort                 = 3306
+)
+
+func mysqlUsageMessage(prg_name string) error {
+	return fmt.Errorf(`%s - database data exporter [version %s]
+
+Usage:
+  %s mysql -h <hostname> -d <database name> -u <username> -P <password>
+
+Example:
+  mysql(MySQL/MariaDB):
+    DB_PUKE_PASSWORD=mysqlPassword1234 %s mysql -h localhost -d dummy_database -u root
+
+See more:
+  '%s <database type> --help'
+`, prg_name, DBPukeVersion, prg_name, prg_name, prg_name)
+}
+
+func setMysqlFlag(option *Option, fs *flag.FlagSet) {
+	fs.StringVar(&option.Host, "h", "localhost", "database server host")
+	fs.StringVar(&option.PortString, "p", "", "database server port")
+	fs.StringVar(&option.Database, "d", "", "database")
+	fs.StringVar(&option.User, "u", "", "database user name")
+	fs.StringVar(&option.Password, "P", "", "database user password(or use DB_PUKE_PASSWORD env var)")
+}
+
+func validateMysqlOption(option *Option) error {
+	if option.Database == "" {
+		return fmt.Errorf(MysqlNoSpecifiedDatabaseMessage)
+	}
+	if option.User == "" {
+		return fmt.Errorf(MysqlNoSpecifiedUserMessage)
+	}
+	if option.PortString == "" {
+		option.Port = MysqlDefaultPort
+	} else {
+		port, err := strconv.Atoi(option.PortString)
+		if err != nil {
+			return fmt.Errorf(MysqlInvalidPortSpecifiedMessage)
+		}
+		option.Port = port
+	}
+
+	return nil
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"io"
+	"testing"
+)
+
+func TestMysqlValidMinimumArgs(t *testing.T) {
+	option, err := parseArgs([]string{
+		"db-puke",
+		"mysql",
+		"-h",
+		"localhost",
+		"-d",
+		"dummy_database",
+		"-u",
+		"root",
+		"-P",
+		"mysqlPassword",
+	}, io.Discard)
+	if err != nil {
+		t.Fatalf("call by valid args. want: nil, but got %s", err.Error())
+	}
+
+	if option.DBType != DBTypeMySQL {
+		t.Errorf("option.DBType want: %s, but got %s", DBTypeMySQL, option.DBType)
+	}
+
+	if option.Host != "localhost" {
+		t.Errorf("option.Host want: %s, but got %s", "localhost", option.Host)
+	}
+
+	if option.Database != "dummy_database" {
+		t.Errorf("option.Database want: %s, but got %s", "dummy_database", option.Database)
+	}
+
+	if option.User != "root" {
+		t.Errorf("option.User want: %s, but got %s", "root", option.User)
+	}
+
+	if option.Password != "mysqlPassword" {
+		t.Errorf("option.Password want: %s, but got %s", "mysqlPassword", option.Password)
+	}
+
+	if option.Port != MysqlDefaultPort {
+		t.Errorf("option.Port want: %d, but got %d", MysqlDefaultPort, option.Port)
+	}
+}
+
+func TestMysqlSubcommandHelpArgs(t *testing.T) {
+	_, err := parseArgs([]string{
+		"db-puke",
+		"mysql",
+		"--help",
+	}, io.Discard)
+	if err == nil {
+		t.Errorf("call by --help args. want return error, but got nil")
+	}
+}
+
+func TestMysqlNoSpecifiedDatabase(t *testing.T) {
+	_, err := parseArgs([]string{
+		"db-puke",
+		"mysql",
+		"-u",
+		"root",
+	}, io.Discard)
+
+	if err == nil {
+		t.Fatalf("call by invalid args. want error: '%s', but got nil", MysqlNoSpecifiedDatabaseMessage)
+	}
+
+	if err.Error() != MysqlNoSpecifiedDatabaseMessage {
+		t.Fatalf("call by invalid args. want error: '%s', but got '%s'", MysqlNoSpecifiedDatabaseMessage, err.Error())
+	}
+}
+
+func TestMysqlNoSpecifiedUser(t *testing.T) {
+	_, err := parseArgs([]string{
+		"db-puke",
+		"mysql",
+		"-d",
+		"dummy_database",
+	}, io.Discard)
+
+	if err == nil {
+		t.Fatalf("call by invalid args. want error: '%s', but got nil", MysqlNoSpecifiedUserMessage)
+	}
+
+	if err.Error() != MysqlNoSpecifiedUserMessage {
+		t.Fatalf("call by invalid args. want error: '%s', but got '%s'", MysqlNoSpecifiedUserMessage, err.Error())
+	}
+}
+
+func TestMysqlInvalidPortSpecified(t *testing.T) {
+	_, err := parseArgs([]string{
+		"db-puke",
+		"mysql",
+		"-d",
+		"dummy_database",
+		"-u",
+		"root",
+		"-p",
+		"foo",
+	}, io.Discard)
+
+	if err == nil {
+		t.Fatalf("call by invalid args. want error: '%s', but got nil", MysqlInvalidPortSpecifiedMessage)
+	}
+
+	if err.Error() != MysqlInvalidPortSpecifiedMessage {
+		t.Fatalf("call by invalid args. want error: '%s', but got '%s'", MysqlInvalidPortSpecifiedMessage, err.Error())
+	}
+}
@@ -0,0 +1,369 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+type MySQLOperator struct {
+	connString string
+	db         *sql.DB
+	tx         *sql.Tx
+}
+
+func NewMySQLOperator() *MySQLOperator {
+	cfg := mysql.NewConfig()
+	cfg.User = commandOption.User
+	cfg.Passwd = commandOption.Password
+	cfg.Net = "tcp"
+	cfg.Addr = fmt.Sprintf("%s:%d", commandOption.Host, commandOption.Port)
+	cfg.DBName = commandOption.Database
+	return &MySQLOperator{connString: cfg.FormatDSN()}
+}
+
+func (o *MySQLOperator) DBOpen(ctx context.Context) error {
+	db, err := sql.Open("mysql", o.connString)
+	if err != nil {
+		return err
+	}
+	o.db = db
+	db.SetMaxOpenConns(maxOpenConns())
+
+	err = db.PingContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (o *MySQLOperator) DBClose() error {
+	return o.db.Close()
+}
+
+// BeginSnapshot opens a read only REPEATABLE READ transaction. InnoDB
+// reads a consistent snapshot taken at its first query.
+func (o *MySQLOperator) BeginSnapshot(ctx context.Context) error {
+	tx, err := o.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	o.tx = tx
+	return nil
+}
+
+// records returns where table data is read from, which is the snapshot
+// transaction while one is open.
+func (o *MySQLOperator) records() queryer {
+	if o.tx != nil {
+		return o.tx
+	}
+	return o.db
+}
+
+func (o *MySQLOperator) EndSnapshot() error {
+	if o.tx == nil {
+		return nil
+	}
+	err := o.tx.Commit()
+	o.tx = nil
+	return err
+}
+
+func (o *MySQLOperator) GetTableNames(ctx context.Context) ([]TableObject, error) {
+	db := o.db
+	database := commandOption.Database
+
+	query := `
+		SELECT
+			TABLE_SCHEMA,
+			TABLE_NAME,
+			TABLE_TYPE
+		FROM
+			INFORMATION_SCHEMA.TABLES
+		WHERE
+			TABLE_TYPE IN ('BASE TABLE', 'VIEW')
+		AND
+			TABLE_SCHEMA = ?
+	`
+	rows, err := db.QueryContext(ctx, query, database)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tables []TableObject
+	for rows.Next() {
+		var schema, tname, tableType string
+
+		err := rows.Scan(&schema, &tname, &tableType)
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, TableObject{Name: tname, Kind: tableTypeKind(tableType)})
+	}
+	return tables, nil
+}
+
+func (o *MySQLOperator) QueryAllRecords(ctx context.Context, table string) (*sql.Rows, error) {
+	db := o.records()
+
+	query, args, err := tableQuery(o, selectList(o, table), table, func(n int) string {
+		return "?"
+	})
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
+
+func (o *MySQLOperator) QueryRecords(ctx context.Context, query string, params map[string]string) (*sql.Rows, error) {
+	query, args, err := bindParams(query, params, func(n int) string {
+		return "?"
+	})
+	if err != nil {
+		return nil, err
+	}
+	return o.records().QueryContext(ctx, query, args...)
+}
+
+func (o *MySQLOperator) ColumnTypes(ctx context.Context, table string) ([]*sql.ColumnType, error) {
+	return queryColumnTypes(ctx, o.db, fmt.Sprintf("SELECT * FROM %s WHERE 1 = 0", o.QualifiedTableName(table)))
+}
+
+func (o *MySQLOperator) CastToText(expr string) string {
+	return fmt.Sprintf("CAST(%s AS CHAR)", expr)
+}
+
+func (o *MySQLOperator) LimitQuery(query string, limit int64) string {
+	return fmt.Sprintf("%s LIMIT %d", query, limit)
+}
+
+// formatMysqlInteger formats an integer value. The driver returns int64,
+// uint64 for BIGINT UNSIGNED values, and the text of the value for those
+// above MaxInt64 read by the binary protocol of queries with arguments.
+func formatMysqlInteger(val any) string {
+	switch v := val.(type) {
+	case []byte:
+		return string(v)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	}
+	return fmt.Sprintf("%d", val)
+}
+
+func (o *MySQLOperator) FormatData(val any, ty *sql.ColumnType) (string, error) {
+	if val == nil {
+		return commandOption.NullRepresent, nil
+	}
+	// unsigned integer types are reported as e.g. "UNSIGNED INT"
+	tyname := strings.TrimPrefix(ty.DatabaseTypeName(), "UNSIGNED ")
+
+	switch tyname {
+	case "TINYINT":
+		fallthrough
+	case "SMALLINT":
+		fallthrough
+	case "MEDIUMINT":
+		fallthrough
+	case "INT":
+		fallthrough
+	case "BIGINT":
+		fallthrough
+	case "YEAR":
+		return formatMysqlInteger(val), nil
+	case "BIT":
+		v := val.([]uint8)
+		var n uint64
+		for _, b := range v {
+			n = n<<8 | uint64(b)
+		}
+		return fmt.Sprintf("%d", n), nil
+	case "FLOAT":
+		fallthrough
+	case "DOUBLE":
+		return fmt.Sprintf("%g", val), nil
+	case "DECIMAL":
+		v := val.([]uint8)
+		return string(v), nil
+	case "CHAR":
+		fallthrough
+	case "VARCHAR":
+		fallthrough
+	case "TINYTEXT":
+		fallthrough
+	case "TEXT":
+		fallthrough
+	case "MEDIUMTEXT":
+		fallthrough
+	case "LONGTEXT":
+		fallthrough
+	case "ENUM":
+		fallthrough
+	case "SET":
+		fallthrough
+	case "JSON":
+		return fmt.Sprintf("%s", val), nil
+	case "DATE":
+		fallthrough
+	case "DATETIME":
+		fallthrough
+	case "TIMESTAMP":
+		fallthrough
+	case "TIME":
+		// temporal values are kept in the server's text form, which
+		// carries exactly the fractional digits of the column definition.
+		v := val.([]uint8)
+		return string(v), nil
+	case "BINARY":
+		fallthrough
+	case "VARBINARY":
+		fallthrough
+	case "TINYBLOB":
+		fallthrough
+	case "BLOB":
+		fallthrough
+	case "MEDIUMBLOB":
+		fallthrough
+	case "LONGBLOB":
+		v := val.([]uint8)
+		return "0x" + strings.ToUpper(hex.EncodeToString(v)), nil
+	}
+
+	return UnsupportedColumnTypeOutput, nil
+}
+
+func (o *MySQLOperator) ColumnKind(ty *sql.ColumnType) ColumnKind {
+	switch strings.TrimPrefix(ty.DatabaseTypeName(), "UNSIGNED ") {
+	case "TINYINT", "SMALLINT", "MEDIUMINT", "INT", "BIGINT", "YEAR", "BIT":
+		return ColumnKindInteger
+	case "FLOAT", "DOUBLE":
+		return ColumnKindFloat
+	case "DECIMAL":
+		return ColumnKindDecimal
+	case "CHAR", "VARCHAR", "TINYTEXT", "TEXT", "MEDIUMTEXT", "LONGTEXT", "ENUM", "SET", "TIME":
+		return ColumnKindString
+	case "JSON":
+		return ColumnKindJSON
+	case "DATE":
+		return ColumnKindDate
+	case "DATETIME", "TIMESTAMP":
+		return ColumnKindDateTime
+	case "BINARY", "VARBINARY", "TINYBLOB", "BLOB", "MEDIUMBLOB", "LONGBLOB":
+		return ColumnKindBinary
+	}
+	return ColumnKindUnsupported
+}
+
+func (o *MySQLOperator) DecimalSize(ty *sql.ColumnType) (precision, scale int64, ok bool) {
+	return ty.DecimalSize()
+}
+
+func (o *MySQLOperator) RowCountEstimates(ctx context.Context) (map[string]int64, error) {
+	query := `
+		SELECT
+			TABLE_SCHEMA,
+			TABLE_NAME,
+			COALESCE(TABLE_ROWS, 0)
+		FROM
+			INFORMATION_SCHEMA.TABLES
+		WHERE
+			TABLE_SCHEMA = ?
+	`
+	return queryRowCountEstimates(ctx, o.db, query, commandOption.Database)
+}
+
+func (o *MySQLOperator) PrimaryKeyColumns(ctx context.Context, table string) ([]string, error) {
+	query := `
+		SELECT
+			kcu.COLUMN_NAME
+		FROM
+			INFORMATION_SCHEMA.TABLE_CONSTRAINTS tc
+		INNER JOIN
+			INFORMATION_SCHEMA.KEY_COLUMN_USAGE kcu
+			ON kcu.CONSTRAINT_SCHEMA = tc.CONSTRAINT_SCHEMA
+			AND kcu.CONSTRAINT_NAME = tc.CONSTRAINT_NAME
+			AND kcu.TABLE_NAME = tc.TABLE_NAME
+		WHERE
+			tc.CONSTRAINT_TYPE = 'PRIMARY KEY'
+		AND
+			tc.TABLE_SCHEMA = ?
+		AND
+			tc.TABLE_NAME = ?
+		ORDER BY
+			kcu.ORDINAL_POSITION
+	`
+	return queryPrimaryKeyColumns(ctx, o.db, query, commandOption.Database, table)
+}
+
+func (o *MySQLOperator) ColumnRange(ctx context.Context, table string, column string) (min, max any, err error) {
+	return queryColumnRange(ctx, o.records(), o.QualifiedTableName(table), o.QuoteIdentifier(column))
+}
+
+func (o *MySQLOperator) QueryRecordsInRange(ctx context.Context, table string, column string, r KeyRange) (*sql.Rows, error) {
+	query, args := rangeQuery(selectList(o, table), o.QualifiedTableName(table), o.QuoteIdentifier(column), r, func(n int) string {
+		return "?"
+	})
+	return o.records().QueryContext(ctx, query, args...)
+}
+
+func (o *MySQLOperator) QualifiedTableName(table string) string {
+	return quoteMysqlIdentifier(commandOption.Database) + "." + quoteMysqlIdentifier(table)
+}
+
+func (o *MySQLOperator) QuoteIdentifier(name string) string {
+	return quoteMysqlIdentifier(name)
+}
+
+func (o *MySQLOperator) SQLLiteral(val any, ty *sql.ColumnType) (string, error) {
+	if val == nil {
+		return "NULL", nil
+	}
+
+	switch o.ColumnKind(ty) {
+	case ColumnKindBinary:
+		return "X'" + strings.ToUpper(hex.EncodeToString(val.([]byte))) + "'", nil
+	}
+
+	s, err := o.FormatData(val, ty)
+	if err != nil {
+		return "", err
+	}
+
+	switch o.ColumnKind(ty) {
+	case ColumnKindInteger, ColumnKindFloat, ColumnKindDecimal:
+		return s, nil
+	}
+	return quoteMysqlString(s), nil
+}
+
+func (o *MySQLOperator) IdentityInsert(ctx context.Context, table string) (on, off string, err error) {
+	// MySQL accepts explicit values for AUTO_INCREMENT columns.
+	return "", "", nil
+}
+
+// quoteMysqlString quotes s as a string literal that is read back the same
+// regardless of the NO_BACKSLASH_ESCAPES sql mode.
+func quoteMysqlString(s string) string {
+	if strings.Contains(s, `\`) {
+		// a backslash is an escape character unless NO_BACKSLASH_ESCAPES
+		// is set, so the value is written as a hex string instead.
+		return "_utf8mb4 X'" + strings.ToUpper(hex.EncodeToString([]byte(s))) + "'"
+	}
+	return quoteSQLString(s)
+}
+
+func quoteMysqlIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
@@ -0,0 +1,494 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"reflect"
+	"testing"
+
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/reader"
+)
+
+var mysqlTestOption = &Option{
+	DBType:        DBTypeMySQL,
+	Host:          "127.0.0.1",
+	Port:          3306,
+	Database:      "dummy_database",
+	User:          "root",
+	Password:      "mysqlPassword1234",
+	OutDir:        "",
+	NullRepresent: "NULL",
+}
+
+func execMysqlTestSQL(query string) {
+	connString := fmt.Sprintf("%s:%s@tcp(%s:%d)/?multiStatements=true",
+		mysqlTestOption.User, mysqlTestOption.Password, mysqlTestOption.Host, mysqlTestOption.Port)
+
+	db, err := sql.Open("mysql", connString)
+	if err != nil {
+		log.Fatal("Error: Failed to connect to the database", err)
+	}
+	defer db.Close()
+
+	_, err = db.Exec(query)
+	if err != nil {
+		log.Fatal("Error: Failed to Exec", err)
+	}
+}
+
+func createTestDatabaseMysql() {
+	execMysqlTestSQL(`
+		DROP DATABASE IF EXISTS dummy_database;
+		CREATE DATABASE dummy_database CHARACTER SET utf8mb4;
+	`)
+}
+
+func TestMysqlIntColumn(t *testing.T) {
+	skipIfShort(t)
+
+	// Create table for test
+	execMysqlTestSQL(`
+		USE dummy_database;
+		DROP TABLE IF EXISTS test_int_column_table;
+		CREATE TABLE test_int_column_table (
+			id INT NOT NULL PRIMARY KEY,
+			tinyint_col TINYINT NOT NULL,
+			smallint_col SMALLINT NOT NULL,
+			mediumint_col MEDIUMINT NOT NULL,
+			bigint_col BIGINT NOT NULL,
+			unsigned_bigint_col BIGINT UNSIGNED NOT NULL
+		);
+	`)
+	// Insert test data
+	execMysqlTestSQL(`
+		USE dummy_database;
+		INSERT INTO test_int_column_table VALUES (-2147483648, -128, -32768, -8388608, -9223372036854775808, 0);
+		INSERT INTO test_int_column_table VALUES (0, 0, 0, 0, 0, 0);
+		INSERT INTO test_int_column_table VALUES (2147483647, 127, 32767, 8388607, 9223372036854775807, 18446744073709551615);
+	`)
+
+	mysqlTestOption.OutDir = "testoutdir/mysql"
+	commandOption = mysqlTestOption
+	exec(context.Background())
+
+	AssertCompareFiles(t, "testoutdir/mysql/test_int_column_table.csv", "testdata/mysql/test_int_column_table.csv")
+}
+
+func TestMysqlUnsignedBigintWithArguments(t *testing.T) {
+	skipIfShort(t)
+
+	// Create table for test
+	execMysqlTestSQL(`
+		USE dummy_database;
+		DROP TABLE IF EXISTS test_unsigned_bigint_arguments;
+		CREATE TABLE test_unsigned_bigint_arguments (
+			id INT NOT NULL PRIMARY KEY,
+			unsigned_bigint_col BIGINT UNSIGNED NOT NULL
+		);
+	`)
+	// Insert test data
+	execMysqlTestSQL(`
+		USE dummy_database;
+		INSERT INTO test_unsigned_bigint_arguments VALUES (1, 0);
+		INSERT INTO test_unsigned_bigint_arguments VALUES (2, 9223372036854775807);
+		INSERT INTO test_unsigned_bigint_arguments VALUES (3, 18446744073709551615);
+	`)
+
+	// a query with arguments is run with the binary protocol.
+	mysqlTestOption.OutDir = "testoutdir/mysql/arguments"
+	mysqlTestOption.ParsedTableNames = []string{"test_unsigned_bigint_arguments"}
+	mysqlTestOption.TableFilters = map[string]*TableFilter{"test_unsigned_bigint_arguments": {Where: "id >= :min"}}
+	mysqlTestOption.Params = map[string]string{"min": "1"}
+	defer func() {
+		mysqlTestOption.ParsedTableNames = nil
+		mysqlTestOption.TableFilters = nil
+		mysqlTestOption.Params = nil
+	}()
+	commandOption = mysqlTestOption
+	exec(context.Background())
+
+	AssertCompareFiles(t, "testoutdir/mysql/arguments/test_unsigned_bigint_arguments.csv", "testdata/mysql/test_unsigned_bigint_arguments.csv")
+}
+
+func TestMysqlParquetUnsignedBigint(t *testing.T) {
+	skipIfShort(t)
+
+	// Create table for test
+	execMysqlTestSQL(`
+		USE dummy_database;
+		DROP TABLE IF EXISTS test_parquet_unsigned_bigint;
+		CREATE TABLE test_parquet_unsigned_bigint (
+			id INT NOT NULL PRIMARY KEY,
+			unsigned_bigint_col BIGINT UNSIGNED
+		);
+	`)
+	// Insert test data
+	execMysqlTestSQL(`
+		USE dummy_database;
+		INSERT INTO test_parquet_unsigned_bigint VALUES (1, 18446744073709551615);
+		INSERT INTO test_parquet_unsigned_bigint VALUES (2, NULL);
+	`)
+
+	mysqlTestOption.OutDir = "testoutdir/mysql"
+	mysqlTestOption.Format = OutputFormatParquet
+	mysqlTestOption.ParsedTableNames = []string{"test_parquet_unsigned_bigint"}
+	defer func() {
+		mysqlTestOption.Format = OutputFormatCSV
+		mysqlTestOption.ParsedTableNames = nil
+	}()
+	commandOption = mysqlTestOption
+	exec(context.Background())
+
+	fr, err := local.NewLocalFileReader("testoutdir/mysql/test_parquet_unsigned_bigint.parquet")
+	if err != nil {
+		t.Fatalf("open parquet file failed: %v", err)
+	}
+	defer fr.Close()
+
+	pr, err := reader.NewParquetColumnReader(fr, 1)
+	if err != nil {
+		t.Fatalf("read parquet file failed: %v", err)
+	}
+	defer pr.ReadStop()
+
+	if element := pr.SchemaHandler.SchemaElements[2]; element.GetConvertedType() != parquet.ConvertedType_UINT_64 {
+		t.Errorf("unsigned_bigint_col converted type want: UINT_64, but got %v", element.GetConvertedType())
+	}
+	// the bits of the maximum uint64 as an int64.
+	got, _, _, err := pr.ReadColumnByIndex(1, 2)
+	if err != nil {
+		t.Fatalf("read column failed: %v", err)
+	}
+	if want := []interface{}{int64(-1), nil}; !reflect.DeepEqual(got, want) {
+		t.Errorf("want: %v, but got %v", want, got)
+	}
+}
+
+func TestFormatMysqlInteger(t *testing.T) {
+	tests := []struct {
+		val  any
+		want string
+	}{
+		{int64(-9223372036854775808), "-9223372036854775808"},
+		{uint64(18446744073709551615), "18446744073709551615"},
+		{[]byte("18446744073709551615"), "18446744073709551615"},
+	}
+	for _, tt := range tests {
+		if got := formatMysqlInteger(tt.val); got != tt.want {
+			t.Errorf("want: %s, but got %s", tt.want, got)
+		}
+	}
+}
+
+func TestMysqlBoolColumn(t *testing.T) {
+	skipIfShort(t)
+
+	// Create table for test
+	execMysqlTestSQL(`
+		USE dummy_database;
+		DROP TABLE IF EXISTS test_bool_column_table;
+		CREATE TABLE test_bool_column_table (
+			id INT NOT NULL PRIMARY KEY,
+			bool_col TINYINT(1)
+		);
+	`)
+	// Insert test data
+	execMysqlTestSQL(`
+		USE dummy_database;
+		INSERT INTO test_bool_column_table VALUES (1, true);
+		INSERT INTO test_bool_column_table VALUES (2, false);
+		INSERT INTO test_bool_column_table VALUES (3, NULL);
+	`)
+
+	mysqlTestOption.OutDir = "testoutdir/mysql"
+	commandOption = mysqlTestOption
+	exec(context.Background())
+
+	AssertCompareFiles(t, "testoutdir/mysql/test_bool_column_table.csv", "testdata/mysql/test_bool_column_table.csv")
+}
+
+func TestMysqlDecimalColumn(t *testing.T) {
+	skipIfShort(t)
+
+	// Create table for test
+	execMysqlTestSQL(`
+		USE dummy_database;
+		DROP TABLE IF EXISTS test_decimal_column_table;
+		CREATE TABLE test_decimal_column_table (
+			decimal_col DECIMAL(15, 3) NOT NULL PRIMARY KEY
+		);
+	`)
+	// Insert test data
+	execMysqlTestSQL(`
+		USE dummy_database;
+		INSERT INTO test_decimal_column_table VALUES (-999999999999.999);
+		INSERT INTO test_decimal_column_table VALUES (0);
+		INSERT INTO test_decimal_column_table VALUES (999999999999.999);
+	`)
+
+	mysqlTestOption.OutDir = "testoutdir/mysql"
+	commandOption = mysqlTestOption
+	exec(context.Background())
+
+	AssertCompareFiles(t, "testoutdir/mysql/test_decimal_column_table.csv", "testdata/mysql/test_decimal_column_table.csv")
+}
+
+func TestMysqlFloatColumn(t *testing.T) {
+	skipIfShort(t)
+
+	// Create table for test
+	execMysqlTestSQL(`
+		USE dummy_database;
+		DROP TABLE IF EXISTS test_float_column_table;
+		CREATE TABLE test_float_column_table (
+			id INT NOT NULL PRIMARY KEY,
+			float_col FLOAT,
+			double_col DOUBLE
+		);
+	`)
+	// Insert test data
+	execMysqlTestSQL(`
+		USE dummy_database;
+		INSERT INTO test_float_column_table VALUES (1, -3.14, -1.79E+308);
+		INSERT INTO test_float_column_table VALUES (2, 0, 0);
+		INSERT INTO test_float_column_table VALUES (3, 3.14, 2.23E-308);
+		INSERT INTO test_float_column_table VALUES (4, NULL, NULL);
+	`)
+
+	mysqlTestOption.OutDir = "testoutdir/mysql"
+	commandOption = mysqlTestOption
+	exec(context.Background())
+
+	AssertCompareFiles(t, "testoutdir/mysql/test_float_column_table.csv", "testdata/mysql/test_float_column_table.csv")
+}
+
+func TestMysqlStringColumn(t *testing.T) {
+	skipIfShort(t)
+
+	// Create table for test
+	execMysqlTestSQL(`
+		USE dummy_database;
+		DROP TABLE IF EXISTS test_string_column_table;
+		CREATE TABLE test_string_column_table (
+			id INT NOT NULL PRIMARY KEY,
+			char_col CHAR(5),
+			varchar_col VARCHAR(16),
+			text_col TEXT
+		);
+	`)
+	// Insert test data
+	execMysqlTestSQL(`
+		USE dummy_database;
+		INSERT INTO test_string_column_table VALUES (1, 'abc', 'abc', 'abc');
+		INSERT INTO test_string_column_table VALUES (2, '', '', '');
+		INSERT INTO test_string_column_table VALUES (3, 'テスト', 'テスト', 'テスト');
+		INSERT INTO test_string_column_table VALUES (4, 'a b', 'a"b', 'TEST,STRING');
+		INSERT INTO test_string_column_table VALUES (5, NULL, NULL, NULL);
+	`)
+
+	mysqlTestOption.OutDir = "testoutdir/mysql"
+	commandOption = mysqlTestOption
+	exec(context.Background())
+
+	AssertCompareFiles(t, "testoutdir/mysql/test_string_column_table.csv", "testdata/mysql/test_string_column_table.csv")
+}
+
+func TestMysqlDateTimeColumn(t *testing.T) {
+	skipIfShort(t)
+
+	// Create table for test
+	execMysqlTestSQL(`
+		USE dummy_database;
+		DROP TABLE IF EXISTS test_datetime_column_table;
+		CREATE TABLE test_datetime_column_table (
+			id INT NOT NULL PRIMARY KEY,
+			date_col DATE,
+			datetime_col DATETIME,
+			datetime3_col DATETIME(3),
+			datetime6_col DATETIME(6),
+			time_col TIME
+		);
+	`)
+	// Insert test data
+	execMysqlTestSQL(`
+		USE dummy_database;
+		INSERT INTO test_datetime_column_table VALUES (1, '2025-03-22', '2025-03-22 21:54:24', '2025-03-22 21:54:24', '2025-03-22 21:54:24', '21:54:24');
+		INSERT INTO test_datetime_column_table VALUES (2, '2025-03-22', '2025-03-22 21:54:24', '2025-03-22 21:54:24.123', '2025-03-22 21:54:24.123456', '-838:59:59');
+		INSERT INTO test_datetime_column_table VALUES (3, NULL, NULL, NULL, NULL, NULL);
+	`)
+
+	mysqlTestOption.OutDir = "testoutdir/mysql"
+	commandOption = mysqlTestOption
+	exec(context.Background())
+
+	AssertCompareFiles(t, "testoutdir/mysql/test_datetime_column_table.csv", "testdata/mysql/test_datetime_column_table.csv")
+}
+
+func TestMysqlYearColumn(t *testing.T) {
+	skipIfShort(t)
+
+	// Create table for test
+	execMysqlTestSQL(`
+		USE dummy_database;
+		DROP TABLE IF EXISTS test_year_column_table;
+		CREATE TABLE test_year_column_table (
+			year_col YEAR NOT NULL PRIMARY KEY
+		);
+	`)
+	// Insert test data
+	execMysqlTestSQL(`
+		USE dummy_database;
+		INSERT INTO test_year_column_table VALUES (1901);
+		INSERT INTO test_year_column_table VALUES (2025);
+		INSERT INTO test_year_column_table VALUES (2155);
+	`)
+
+	mysqlTestOption.OutDir = "testoutdir/mysql"
+	commandOption = mysqlTestOption
+	exec(context.Background())
+
+	AssertCompareFiles(t, "testoutdir/mysql/test_year_column_table.csv", "testdata/mysql/test_year_column_table.csv")
+}
+
+func TestMysqlEnumSetColumn(t *testing.T) {
+	skipIfShort(t)
+
+	// Create table for test
+	execMysqlTestSQL(`
+		USE dummy_database;
+		DROP TABLE IF EXISTS test_enum_set_column_table;
+		CREATE TABLE test_enum_set_column_table (
+			id INT NOT NULL PRIMARY KEY,
+			enum_col ENUM('small', 'medium', 'large'),
+			set_col SET('a', 'b', 'c')
+		);
+	`)
+	// Insert test data
+	execMysqlTestSQL(`
+		USE dummy_database;
+		INSERT INTO test_enum_set_column_table VALUES (1, 'small', 'a,c');
+		INSERT INTO test_enum_set_column_table VALUES (2, 'large', '');
+		INSERT INTO test_enum_set_column_table VALUES (3, NULL, NULL);
+	`)
+
+	mysqlTestOption.OutDir = "testoutdir/mysql"
+	commandOption = mysqlTestOption
+	exec(context.Background())
+
+	AssertCompareFiles(t, "testoutdir/mysql/test_enum_set_column_table.csv", "testdata/mysql/test_enum_set_column_table.csv")
+}
+
+func TestMysqlJsonColumn(t *testing.T) {
+	skipIfShort(t)
+
+	// Create table for test
+	execMysqlTestSQL(`
+		USE dummy_database;
+		DROP TABLE IF EXISTS test_json_column_table;
+		CREATE TABLE test_json_column_table (
+			id INT NOT NULL PRIMARY KEY,
+			json_col JSON
+		);
+	`)
+	// Insert test data
+	execMysqlTestSQL(`
+		USE dummy_database;
+		INSERT INTO test_json_column_table VALUES (1, '{"a":1}');
+		INSERT INTO test_json_column_table VALUES (2, '[1,2,3]');
+		INSERT INTO test_json_column_table VALUES (3, NULL);
+	`)
+
+	mysqlTestOption.OutDir = "testoutdir/mysql"
+	commandOption = mysqlTestOption
+	exec(context.Background())
+
+	AssertCompareFiles(t, "testoutdir/mysql/test_json_column_table.csv", "testdata/mysql/test_json_column_table.csv")
+}
+
+func TestMysqlBlobColumn(t *testing.T) {
+	skipIfShort(t)
+
+	// Create table for test
+	execMysqlTestSQL(`
+		USE dummy_database;
+		DROP TABLE IF EXISTS test_blob_column_table;
+		CREATE TABLE test_blob_column_table (
+			id INT NOT NULL PRIMARY KEY,
+			blob_col BLOB,
+			varbinary_col VARBINARY(8)
+		);
+	`)
+	// Insert test data
+	execMysqlTestSQL(`
+		USE dummy_database;
+		INSERT INTO test_blob_column_table VALUES (1, 0xDEADBEEF, 0x00FF);
+		INSERT INTO test_blob_column_table VALUES (2, '', '');
+		INSERT INTO test_blob_column_table VALUES (3, NULL, NULL);
+	`)
+
+	mysqlTestOption.OutDir = "testoutdir/mysql"
+	commandOption = mysqlTestOption
+	exec(context.Background())
+
+	AssertCompareFiles(t, "testoutdir/mysql/test_blob_column_table.csv", "testdata/mysql/test_blob_column_table.csv")
+}
+
+func TestMysqlBitColumn(t *testing.T) {
+	skipIfShort(t)
+
+	// Create table for test
+	execMysqlTestSQL(`
+		USE dummy_database;
+		DROP TABLE IF EXISTS test_bit_column_table;
+		CREATE TABLE test_bit_column_table (
+			id INT NOT NULL PRIMARY KEY,
+			bit1_col BIT(1),
+			bit16_col BIT(16)
+		);
+	`)
+	// Insert test data
+	execMysqlTestSQL(`
+		USE dummy_database;
+		INSERT INTO test_bit_column_table VALUES (1, b'0', b'0');
+		INSERT INTO test_bit_column_table VALUES (2, b'1', b'1010101010101010');
+		INSERT INTO test_bit_column_table VALUES (3, NULL, NULL);
+	`)
+
+	mysqlTestOption.OutDir = "testoutdir/mysql"
+	commandOption = mysqlTestOption
+	exec(context.Background())
+
+	AssertCompareFiles(t, "testoutdir/mysql/test_bit_column_table.csv", "testdata/mysql/test_bit_column_table.csv")
+}
+
+func TestMysqlUnsupportedColumnOutput(t *testing.T) {
+	skipIfShort(t)
+
+	// Create table for test
+	execMysqlTestSQL(`
+		USE dummy_database;
+		DROP TABLE IF EXISTS test_unsupported_column_output;
+		CREATE TABLE test_unsupported_column_output (
+			col1 INT NOT NULL PRIMARY KEY,
+			unsupported_col POINT,
+			col2 VARCHAR(32) NOT NULL
+		);
+	`)
+	// Insert test data
+	execMysqlTestSQL(`
+		USE dummy_database;
+		INSERT INTO test_unsupported_column_output VALUES (1, ST_GeomFromText('POINT(1 2)'), 'test row 1');
+		INSERT INTO test_unsupported_column_output VALUES (2, NULL, 'test row 2');
+	`)
+
+	mysqlTestOption.OutDir = "testoutdir/mysql"
+	commandOption = mysqlTestOption
+	exec(context.Background())
+
+	AssertCompareFiles(t, "testoutdir/mysql/test_unsupported_column_output.csv", "testdata/mysql/test_unsupported_column_output.csv")
+}
@@ -0,0 +1,291 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+// ParquetWriter streams records into a Parquet file. The schema is derived
+// from the column types, and row groups are flushed by the underlying
+// writer whenever they reach its row group size.
+type ParquetWriter struct {
+	columns []parquetColumn
+	writer  *writer.CSVWriter
+}
+
+type parquetColumn struct {
+	name  string
+	kind  ColumnKind
+	scale int64
+	// physical decides how a value is converted before it is handed to the
+	// parquet writer (see parquetColumn.convert).
+	physical string
+	unit     string
+}
+
+func NewParquetWriter(operator DBPukeOperator, columnTypes []*sql.ColumnType, w io.Writer) (*ParquetWriter, error) {
+	columns := make([]parquetColumn, len(columnTypes))
+	md := make([]string, len(columnTypes))
+	for i, ty := range columnTypes {
+		// the names are written into the schema definitions, which are
+		// split on commas and trimmed.
+		if name := ty.Name(); strings.ContainsAny(name, ",\t") || strings.TrimSpace(name) != name {
+			return nil, fmt.Errorf("column '%s': names with a comma, a tab or surrounding spaces cannot be written to parquet", name)
+		}
+		columns[i], md[i] = makeParquetColumn(operator, ty)
+	}
+
+	pw, err := writer.NewCSVWriterFromWriter(md, w, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ParquetWriter{
+		columns: columns,
+		writer:  pw,
+	}, nil
+}
+
+// makeParquetColumn returns the column and its schema definition in the
+// metadata format of github.com/xitongsys/parquet-go.
+//
+// The dates, times and booleans of SQLite are only declared types holding
+// any value, so they are written as strings like their text.
+func makeParquetColumn(operator DBPukeOperator, ty *sql.ColumnType) (parquetColumn, string) {
+	col := parquetColumn{name: ty.Name(), kind: operator.ColumnKind(ty)}
+	tyname := ty.DatabaseTypeName()
+	if commandOption.DBType == DBTypeSQLite {
+		switch col.kind {
+		case ColumnKindBool, ColumnKindDate, ColumnKindDateTime:
+			col.kind = ColumnKindString
+		}
+	}
+	// a time with an offset is a point in time, written in UTC.
+	zoned := tyname == "TIMESTAMPTZ" || tyname == "DATETIMEOFFSET"
+	if zoned {
+		col.kind = ColumnKindDateTime
+	}
+
+	repetition := "OPTIONAL"
+	if nullable, ok := ty.Nullable(); ok && !nullable {
+		repetition = "REQUIRED"
+	}
+
+	var def string
+	switch col.kind {
+	case ColumnKindInteger:
+		if tyname == "UNSIGNED BIGINT" {
+			// UINT_64 values are stored in the bits of an INT64.
+			col.physical = "UINT64"
+			def = "type=INT64, convertedtype=UINT_64"
+			break
+		}
+		col.physical = "INT64"
+		def = "type=INT64"
+	case ColumnKindFloat:
+		col.physical = "DOUBLE"
+		def = "type=DOUBLE"
+	case ColumnKindBool:
+		col.physical = "BOOLEAN"
+		def = "type=BOOLEAN"
+	case ColumnKindDecimal:
+		precision, scale, ok := operator.DecimalSize(ty)
+		if !ok {
+			col.physical = "STRING"
+			def = "type=BYTE_ARRAY, convertedtype=UTF8"
+			break
+		}
+		col.scale = scale
+		if precision <= 18 {
+			col.physical = "DECIMAL_INT64"
+			def = fmt.Sprintf("type=INT64, convertedtype=DECIMAL, precision=%d, scale=%d", precision, scale)
+		} else {
+			col.physical = "DECIMAL_BYTE_ARRAY"
+			def = fmt.Sprintf("type=BYTE_ARRAY, convertedtype=DECIMAL, precision=%d, scale=%d", precision, scale)
+		}
+	case ColumnKindDate:
+		col.physical = "DATE"
+		def = "type=INT32, convertedtype=DATE"
+	case ColumnKindDateTime:
+		col.physical = "TIMESTAMP"
+		col.unit = "MICROS"
+		if _, scale, ok := ty.DecimalSize(); ok {
+			if scale <= 3 {
+				col.unit = "MILLIS"
+			} else if scale > 6 {
+				col.unit = "NANOS"
+			}
+		}
+		def = fmt.Sprintf("type=INT64, logicaltype=TIMESTAMP, logicaltype.isadjustedtoutc=%t, logicaltype.unit=%s", zoned, col.unit)
+	case ColumnKindUUID:
+		col.physical = "UUID"
+		def = "type=FIXED_LEN_BYTE_ARRAY, length=16, logicaltype=UUID"
+	case ColumnKindJSON:
+		col.physical = "STRING"
+		def = "type=BYTE_ARRAY, convertedtype=JSON"
+	case ColumnKindBinary:
+		col.physical = "BINARY"
+		def = "type=BYTE_ARRAY"
+	default:
+		col.physical = "STRING"
+		def = "type=BYTE_ARRAY, convertedtype=UTF8"
+	}
+
+	return col, fmt.Sprintf("name=%s, %s, repetitiontype=%s", col.name, def, repetition)
+}
+
+func (w *ParquetWriter) WriteHeader(columns []string) error {
+	// the column names are part of the schema written by the footer.
+	return nil
+}
+
+func (w *ParquetWriter) WriteRecord(record []string, values []any) error {
+	// the writer keeps the record until its row group is flushed,
+	// so it must not be reused.
+	rec := make([]interface{}, len(w.columns))
+	for i, col := range w.columns {
+		if values[i] == nil {
+			continue
+		}
+		v, err := col.convert(record[i], values[i])
+		if err != nil {
+			return fmt.Errorf("column '%s': %w", col.name, err)
+		}
+		rec[i] = v
+	}
+	return w.writer.Write(rec)
+}
+
+func (w *ParquetWriter) Flush() error {
+	return w.writer.WriteStop()
+}
+
+func (col *parquetColumn) convert(val_str string, val any) (interface{}, error) {
+	switch col.physical {
+	case "INT64":
+		return strconv.ParseInt(val_str, 10, 64)
+	case "UINT64":
+		n, err := strconv.ParseUint(val_str, 10, 64)
+		return int64(n), err
+	case "DOUBLE":
+		return strconv.ParseFloat(val_str, 64)
+	case "BOOLEAN":
+		return strconv.ParseBool(val_str)
+	case "DECIMAL_INT64":
+		n, err := parseUnscaledDecimal(val_str, col.scale)
+		if err != nil {
+			return nil, err
+		}
+		if !n.IsInt64() {
+			return nil, fmt.Errorf("decimal value out of range: %s", val_str)
+		}
+		return n.Int64(), nil
+	case "DECIMAL_BYTE_ARRAY":
+		n, err := parseUnscaledDecimal(val_str, col.scale)
+		if err != nil {
+			return nil, err
+		}
+		return string(twosComplementBytes(n)), nil
+	case "DATE":
+		t, err := time.Parse("2006-01-02", val_str)
+		if err != nil {
+			return nil, err
+		}
+		return int32(t.Unix() / (24 * 60 * 60)), nil
+	case "TIMESTAMP":
+		t, ok := val.(time.Time)
+		if !ok {
+			var err error
+			t, err = parseFormattedTimestamp(val_str)
+			if err != nil {
+				return nil, err
+			}
+		}
+		switch col.unit {
+		case "MILLIS":
+			return t.UnixMilli(), nil
+		case "NANOS":
+			if t.Year() < 1678 || t.Year() > 2261 {
+				return nil, fmt.Errorf("timestamp out of range for nanosecond precision: %s", val_str)
+			}
+			return t.UnixNano(), nil
+		default:
+			return t.UnixMicro(), nil
+		}
+	case "UUID":
+		u, err := uuid.Parse(val_str)
+		if err != nil {
+			return nil, err
+		}
+		return string(u[:]), nil
+	case "BINARY":
+		if b, ok := val.([]byte); ok {
+			return string(b), nil
+		}
+		return val_str, nil
+	}
+	return val_str, nil
+}
+
+// parseUnscaledDecimal converts a decimal string such as "-12.50" into its
+// unscaled integer representation for the given scale (-1250 for scale 2).
+func parseUnscaledDecimal(s string, scale int64) (*big.Int, error) {
+	intPart, fracPart, _ := strings.Cut(s, ".")
+	if int64(len(fracPart)) > scale {
+		return nil, fmt.Errorf("decimal value has more digits than scale %d: %s", scale, s)
+	}
+	fracPart += strings.Repeat("0", int(scale)-len(fracPart))
+
+	n, ok := new(big.Int).SetString(intPart+fracPart, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid decimal value: %s", s)
+	}
+	return n, nil
+}
+
+// twosComplementBytes returns the minimal big-endian two's complement
+// representation of n, as required for BYTE_ARRAY decimals.
+func twosComplementBytes(n *big.Int) []byte {
+	if n.Sign() >= 0 {
+		b := n.Bytes()
+		if len(b) == 0 || b[0]&0x80 != 0 {
+			b = append([]byte{0}, b...)
+		}
+		return b
+	}
+
+	// -n == ^(n-1): take the magnitude minus one and invert it
+	m := new(big.Int).Neg(n)
+	m.Sub(m, big.NewInt(1))
+	b := m.Bytes()
+	if len(b) == 0 || b[0]&0x80 != 0 {
+		b = append([]byte{0}, b...)
+	}
+	for i := range b {
+		b[i] = ^b[i]
+	}
+	return b
+}
+
+func parseFormattedTimestamp(s string) (time.Time, error) {
+	layouts := []string{
+		"2006-01-02 15:04:05.999999999",
+		"2006-01-02 15:04:05.999999999-07:00",
+	}
+	var err error
+	for _, layout := range layouts {
+		var t time.Time
+		if t, err = time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+)
+
+func TestParseUnscaledDecimal(t *testing.T) {
+	tests := []struct {
+		in    string
+		scale int64
+		want  string
+	}{
+		{"0", 0, "0"},
+		{"12.50", 2, "1250"},
+		{"12.5", 3, "12500"},
+		{"-0.001", 3, "-1"},
+		{"-922337203685477.5808", 4, "-9223372036854775808"},
+	}
+
+	for _, tt := range tests {
+		got, err := parseUnscaledDecimal(tt.in, tt.scale)
+		if err != nil {
+			t.Fatalf("parseUnscaledDecimal(%s, %d) want error: nil, but got '%s'", tt.in, tt.scale, err)
+		}
+		if got.String() != tt.want {
+			t.Errorf("parseUnscaledDecimal(%s, %d) want: %s, but got %s", tt.in, tt.scale, tt.want, got)
+		}
+	}
+
+	if _, err := parseUnscaledDecimal("1.234", 2); err == nil {
+		t.Errorf("parseUnscaledDecimal with too many digits want error, but got nil")
+	}
+	if _, err := parseUnscaledDecimal("NaN", 2); err == nil {
+		t.Errorf("parseUnscaledDecimal with invalid value want error, but got nil")
+	}
+}
+
+func TestTwosComplementBytes(t *testing.T) {
+	tests := []struct {
+		in   int64
+		want []byte
+	}{
+		{0, []byte{0x00}},
+		{1, []byte{0x01}},
+		{127, []byte{0x7f}},
+		{128, []byte{0x00, 0x80}},
+		{-1, []byte{0xff}},
+		{-128, []byte{0x80}},
+		{-129, []byte{0xff, 0x7f}},
+	}
+
+	for _, tt := range tests {
+		got := twosComplementBytes(big.NewInt(tt.in))
+		if !bytes.Equal(got, tt.want) {
+			t.Errorf("twosComplementBytes(%d) want: %x, but got %x", tt.in, tt.want, got)
+		}
+	}
+}
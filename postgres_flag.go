@@ -0,0 +1,71 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+)
+
+const (
+	PostgresNoSpecifiedDatabaseMessage  = "error: please specify the database name (-d)\n"
+	PostgresNoSpecifiedSchemaMessage    = "error: please specify the schema name (-s)\n"
+	PostgresNoSpecifiedUserMessage      = "error: please specify the username (-u)\n"
+	PostgresInvalidPortSpecifiedMessage = "error: invalid port number (-p)\n"
+	PostgresInvalidSSLModeMessage       = "error: invalid sslmode (-sslmode). use disable, require, verify-ca or verify-full\n"
+	PostgresDefaultPort                 = 5432
+	PostgresDefaultSchema               = "public"
+	PostgresDefaultSSLMode              = "disable"
+)
+
+func postgresUsageMessage(prg_name string) error {
+	return fmt.Errorf(`%s - database data exporter [version %s]
+
+Usage:
+  %s postgres -h <hostname> -d <database name> -s <database schema> -u <username> -P <password>
+
+Example:
+  postgres(PostgreSQL):
+    DB_PUKE_PASSWORD=postgresPassword1234 %s postgres -h localhost -d dummy_database -s dummy_schema -u postgres
+
+See more:
+  '%s <database type> --help'
+`, prg_name, DBPukeVersion, prg_name, prg_name, prg_name)
+}
+
+func setPostgresFlag(option *Option, fs *flag.FlagSet) {
+	fs.StringVar(&option.Host, "h", "localhost", "database server host")
+	fs.StringVar(&option.PortString, "p", "", "database server port")
+	fs.StringVar(&option.Database, "d", "", "database")
+	fs.StringVar(&option.Schema, "s", PostgresDefaultSchema, "database schema, comma-separated schemas, or * for all schemas")
+	fs.StringVar(&option.User, "u", "", "database user name")
+	fs.StringVar(&option.Password, "P", "", "database user password(or use DB_PUKE_PASSWORD env var)")
+	fs.StringVar(&option.SSLMode, "sslmode", PostgresDefaultSSLMode, "ssl mode (disable, require, verify-ca, verify-full)")
+}
+
+func validatePostgresOption(option *Option) error {
+	if option.Database == "" {
+		return fmt.Errorf(PostgresNoSpecifiedDatabaseMessage)
+	}
+	if len(parseSchemaOption(option.Schema)) == 0 {
+		return fmt.Errorf(PostgresNoSpecifiedSchemaMessage)
+	}
+	if option.User == "" {
+		return fmt.Errorf(PostgresNoSpecifiedUserMessage)
+	}
+	switch option.SSLMode {
+	case "disable", "require", "verify-ca", "verify-full":
+	default:
+		return fmt.Errorf(PostgresInvalidSSLModeMessage)
+	}
+	if option.PortString == "" {
+		option.Port = PostgresDefaultPort
+	} else {
+		port, err := strconv.Atoi(option.PortString)
+		if err != nil {
+			return fmt.Errorf(PostgresInvalidPortSpecifiedMessage)
+		}
+		option.Port = port
+	}
+
+	return nil
+}
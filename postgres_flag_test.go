@@ -0,0 +1,164 @@
+package main
+
+import (
+	"io"
+	"testing"
+)
+
+func TestPostgresValidMinimumArgs(t *testing.T) {
+	option, err := parseArgs([]string{
+		"db-puke",
+		"postgres",
+		"-h",
+		"localhost",
+		"-d",
+		"dummy_database",
+		"-u",
+		"postgres",
+		"-P",
+		"postgresPassword",
+	}, io.Discard)
+	if err != nil {
+		t.Fatalf("call by valid args. want: nil, but got %s", err.Error())
+	}
+
+	if option.DBType != DBTypePostgres {
+		t.Errorf("option.DBType want: %s, but got %s", DBTypePostgres, option.DBType)
+	}
+
+	if option.Host != "localhost" {
+		t.Errorf("option.Host want: %s, but got %s", "localhost", option.Host)
+	}
+
+	if option.Database != "dummy_database" {
+		t.Errorf("option.Database want: %s, but got %s", "dummy_database", option.Database)
+	}
+
+	if option.User != "postgres" {
+		t.Errorf("option.User want: %s, but got %s", "postgres", option.User)
+	}
+
+	if option.Schema != PostgresDefaultSchema {
+		t.Errorf("option.Schema want: %s, but got %s", PostgresDefaultSchema, option.Schema)
+	}
+
+	if option.Password != "postgresPassword" {
+		t.Errorf("option.Password want: %s, but got %s", "postgresPassword", option.Password)
+	}
+
+	if option.SSLMode != PostgresDefaultSSLMode {
+		t.Errorf("option.SSLMode want: %s, but got %s", PostgresDefaultSSLMode, option.SSLMode)
+	}
+
+	if option.Port != PostgresDefaultPort {
+		t.Errorf("option.Port want: %d, but got %d", PostgresDefaultPort, option.Port)
+	}
+}
+
+func TestPostgresSubcommandHelpArgs(t *testing.T) {
+	_, err := parseArgs([]string{
+		"db-puke",
+		"postgres",
+		"--help",
+	}, io.Discard)
+	if err == nil {
+		t.Errorf("call by --help args. want return error, but got nil")
+	}
+}
+
+func TestPostgresNoSpecifiedDatabase(t *testing.T) {
+	_, err := parseArgs([]string{
+		"db-puke",
+		"postgres",
+		"-u",
+		"postgres",
+	}, io.Discard)
+
+	if err == nil {
+		t.Fatalf("call by invalid args. want error: '%s', but got nil", PostgresNoSpecifiedDatabaseMessage)
+	}
+
+	if err.Error() != PostgresNoSpecifiedDatabaseMessage {
+		t.Fatalf("call by invalid args. want error: '%s', but got '%s'", PostgresNoSpecifiedDatabaseMessage, err.Error())
+	}
+}
+
+func TestPostgresNoSpecifiedSchema(t *testing.T) {
+	_, err := parseArgs([]string{
+		"db-puke",
+		"postgres",
+		"-d",
+		"dummy_database",
+		"-s",
+		"",
+		"-u",
+		"postgres",
+	}, io.Discard)
+
+	if err == nil {
+		t.Fatalf("call by invalid args. want error: '%s', but got nil", PostgresNoSpecifiedSchemaMessage)
+	}
+
+	if err.Error() != PostgresNoSpecifiedSchemaMessage {
+		t.Fatalf("call by invalid args. want error: '%s', but got '%s'", PostgresNoSpecifiedSchemaMessage, err.Error())
+	}
+}
+
+func TestPostgresNoSpecifiedUser(t *testing.T) {
+	_, err := parseArgs([]string{
+		"db-puke",
+		"postgres",
+		"-d",
+		"dummy_database",
+	}, io.Discard)
+
+	if err == nil {
+		t.Fatalf("call by invalid args. want error: '%s', but got nil", PostgresNoSpecifiedUserMessage)
+	}
+
+	if err.Error() != PostgresNoSpecifiedUserMessage {
+		t.Fatalf("call by invalid args. want error: '%s', but got '%s'", PostgresNoSpecifiedUserMessage, err.Error())
+	}
+}
+
+func TestPostgresInvalidSSLMode(t *testing.T) {
+	_, err := parseArgs([]string{
+		"db-puke",
+		"postgres",
+		"-d",
+		"dummy_database",
+		"-u",
+		"postgres",
+		"-sslmode",
+		"foo",
+	}, io.Discard)
+
+	if err == nil {
+		t.Fatalf("call by invalid args. want error: '%s', but got nil", PostgresInvalidSSLModeMessage)
+	}
+
+	if err.Error() != PostgresInvalidSSLModeMessage {
+		t.Fatalf("call by invalid args. want error: '%s', but got '%s'", PostgresInvalidSSLModeMessage, err.Error())
+	}
+}
+
+func TestPostgresInvalidPortSpecified(t *testing.T) {
+	_, err := parseArgs([]string{
+		"db-puke",
+		"postgres",
+		"-d",
+		"dummy_database",
+		"-u",
+		"postgres",
+		"-p",
+		"foo",
+	}, io.Discard)
+
+	if err == nil {
+		t.Fatalf("call by invalid args. want error: '%s', but got nil", PostgresInvalidPortSpecifiedMessage)
+	}
+
+	if err.Error() != PostgresInvalidPortSpecifiedMessage {
+		t.Fatalf("call by invalid args. want error: '%s', but got '%s'", PostgresInvalidPortSpecifiedMessage, err.Error())
+	}
+}
@@ -0,0 +1,347 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type PostgresOperator struct {
+	connString string
+	db         *sql.DB
+	tx         *sql.Tx
+}
+
+func NewPostgresOperator() *PostgresOperator {
+	u := &url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(commandOption.User, commandOption.Password),
+		Host:     fmt.Sprintf("%s:%d", commandOption.Host, commandOption.Port),
+		Path:     commandOption.Database,
+		RawQuery: "sslmode=" + url.QueryEscape(commandOption.SSLMode),
+	}
+	return &PostgresOperator{connString: u.String()}
+}
+
+func (o *PostgresOperator) DBOpen(ctx context.Context) error {
+	db, err := sql.Open("postgres", o.connString)
+	if err != nil {
+		return err
+	}
+	o.db = db
+	db.SetMaxOpenConns(maxOpenConns())
+
+	err = db.PingContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (o *PostgresOperator) DBClose() error {
+	return o.db.Close()
+}
+
+// BeginSnapshot opens a read only REPEATABLE READ transaction, which sees
+// the data as of its first query.
+func (o *PostgresOperator) BeginSnapshot(ctx context.Context) error {
+	tx, err := o.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	o.tx = tx
+	return nil
+}
+
+// records returns where table data is read from, which is the snapshot
+// transaction while one is open.
+func (o *PostgresOperator) records() queryer {
+	if o.tx != nil {
+		return o.tx
+	}
+	return o.db
+}
+
+func (o *PostgresOperator) EndSnapshot() error {
+	if o.tx == nil {
+		return nil
+	}
+	err := o.tx.Commit()
+	o.tx = nil
+	return err
+}
+
+func (o *PostgresOperator) GetTableNames(ctx context.Context) ([]TableObject, error) {
+	db := o.db
+	schemas, args := o.schemaCondition("table_schema")
+
+	query := `
+		SELECT
+			table_schema,
+			table_name,
+			table_type
+		FROM
+			information_schema.tables
+		WHERE
+			table_type IN ('BASE TABLE', 'VIEW')
+		AND
+			` + schemas
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tables []TableObject
+	for rows.Next() {
+		var schema, tname, tableType string
+
+		err := rows.Scan(&schema, &tname, &tableType)
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, TableObject{Name: exportTableName(schema, tname), Kind: tableTypeKind(tableType)})
+	}
+	return tables, nil
+}
+
+// schemaCondition selects the schemas of -s by column.
+func (o *PostgresOperator) schemaCondition(column string) (string, []any) {
+	return schemaCondition(column, []string{"pg_catalog", "information_schema"}, func(n int) string {
+		return fmt.Sprintf("$%d", n)
+	})
+}
+
+func (o *PostgresOperator) QueryAllRecords(ctx context.Context, table string) (*sql.Rows, error) {
+	db := o.records()
+
+	query, args, err := tableQuery(o, selectList(o, table), table, func(n int) string {
+		return fmt.Sprintf("$%d", n)
+	})
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
+
+func (o *PostgresOperator) QueryRecords(ctx context.Context, query string, params map[string]string) (*sql.Rows, error) {
+	query, args, err := bindParams(query, params, func(n int) string {
+		return fmt.Sprintf("$%d", n)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return o.records().QueryContext(ctx, query, args...)
+}
+
+func (o *PostgresOperator) ColumnTypes(ctx context.Context, table string) ([]*sql.ColumnType, error) {
+	return queryColumnTypes(ctx, o.db, fmt.Sprintf("SELECT * FROM %s WHERE 1 = 0", o.QualifiedTableName(table)))
+}
+
+func (o *PostgresOperator) CastToText(expr string) string {
+	return fmt.Sprintf("CAST(%s AS TEXT)", expr)
+}
+
+func (o *PostgresOperator) LimitQuery(query string, limit int64) string {
+	return fmt.Sprintf("%s LIMIT %d", query, limit)
+}
+
+func (o *PostgresOperator) FormatData(val any, ty *sql.ColumnType) (string, error) {
+	if val == nil {
+		return commandOption.NullRepresent, nil
+	}
+	tyname := ty.DatabaseTypeName()
+
+	// array types are reported with a leading underscore (e.g. _INT4)
+	// and are delivered in their text representation.
+	if strings.HasPrefix(tyname, "_") {
+		return fmt.Sprintf("%s", val), nil
+	}
+
+	switch tyname {
+	case "INT2":
+		fallthrough
+	case "INT4":
+		fallthrough
+	case "INT8":
+		return fmt.Sprintf("%d", val), nil
+	case "BOOL":
+		return strconv.FormatBool(val.(bool)), nil
+	case "FLOAT4":
+		fallthrough
+	case "FLOAT8":
+		return fmt.Sprintf("%g", val), nil
+	case "NUMERIC":
+		v := val.([]uint8)
+		return string(v), nil
+	case "VARCHAR":
+		fallthrough
+	case "BPCHAR":
+		fallthrough
+	case "TEXT":
+		return fmt.Sprintf("%s", val), nil
+	case "DATE":
+		t := (val).(time.Time)
+		return t.Format("2006-01-02"), nil
+	case "TIMESTAMP":
+		t := (val).(time.Time)
+		return t.Format("2006-01-02 15:04:05.000000"), nil
+	case "TIMESTAMPTZ":
+		t := (val).(time.Time)
+		return t.Format("2006-01-02 15:04:05.000000-07:00"), nil
+	case "UUID":
+		fallthrough
+	case "JSON":
+		fallthrough
+	case "JSONB":
+		fallthrough
+	case "INTERVAL":
+		v := val.([]uint8)
+		return string(v), nil
+	case "BYTEA":
+		v := val.([]uint8)
+		return `\x` + hex.EncodeToString(v), nil
+	}
+
+	return UnsupportedColumnTypeOutput, nil
+}
+
+func (o *PostgresOperator) ColumnKind(ty *sql.ColumnType) ColumnKind {
+	tyname := ty.DatabaseTypeName()
+	if strings.HasPrefix(tyname, "_") {
+		return ColumnKindString
+	}
+
+	switch tyname {
+	case "INT2", "INT4", "INT8":
+		return ColumnKindInteger
+	case "BOOL":
+		return ColumnKindBool
+	case "FLOAT4", "FLOAT8":
+		return ColumnKindFloat
+	case "NUMERIC":
+		return ColumnKindDecimal
+	case "VARCHAR", "BPCHAR", "TEXT", "INTERVAL":
+		return ColumnKindString
+	case "DATE":
+		return ColumnKindDate
+	case "TIMESTAMP", "TIMESTAMPTZ":
+		return ColumnKindDateTime
+	case "UUID":
+		return ColumnKindUUID
+	case "JSON", "JSONB":
+		return ColumnKindJSON
+	case "BYTEA":
+		return ColumnKindBinary
+	}
+	return ColumnKindUnsupported
+}
+
+func (o *PostgresOperator) DecimalSize(ty *sql.ColumnType) (precision, scale int64, ok bool) {
+	return ty.DecimalSize()
+}
+
+func (o *PostgresOperator) RowCountEstimates(ctx context.Context) (map[string]int64, error) {
+	// reltuples is -1 for tables that have never been analyzed.
+	schemas, args := o.schemaCondition("n.nspname")
+	query := `
+		SELECT
+			n.nspname,
+			c.relname,
+			GREATEST(c.reltuples, 0)::bigint
+		FROM
+			pg_catalog.pg_class c
+		INNER JOIN
+			pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		WHERE
+			c.relkind IN ('r', 'p')
+		AND
+			` + schemas
+	return queryRowCountEstimates(ctx, o.db, query, args...)
+}
+
+func (o *PostgresOperator) PrimaryKeyColumns(ctx context.Context, table string) ([]string, error) {
+	query := `
+		SELECT
+			kcu.COLUMN_NAME
+		FROM
+			INFORMATION_SCHEMA.TABLE_CONSTRAINTS tc
+		INNER JOIN
+			INFORMATION_SCHEMA.KEY_COLUMN_USAGE kcu
+			ON kcu.CONSTRAINT_SCHEMA = tc.CONSTRAINT_SCHEMA
+			AND kcu.CONSTRAINT_NAME = tc.CONSTRAINT_NAME
+			AND kcu.TABLE_NAME = tc.TABLE_NAME
+		WHERE
+			tc.CONSTRAINT_TYPE = 'PRIMARY KEY'
+		AND
+			tc.TABLE_SCHEMA = $1
+		AND
+			tc.TABLE_NAME = $2
+		ORDER BY
+			kcu.ORDINAL_POSITION
+	`
+	schema, name := splitTableName(table)
+	return queryPrimaryKeyColumns(ctx, o.db, query, schema, name)
+}
+
+func (o *PostgresOperator) ColumnRange(ctx context.Context, table string, column string) (min, max any, err error) {
+	return queryColumnRange(ctx, o.records(), o.QualifiedTableName(table), o.QuoteIdentifier(column))
+}
+
+func (o *PostgresOperator) QueryRecordsInRange(ctx context.Context, table string, column string, r KeyRange) (*sql.Rows, error) {
+	query, args := rangeQuery(selectList(o, table), o.QualifiedTableName(table), o.QuoteIdentifier(column), r, func(n int) string {
+		return fmt.Sprintf("$%d", n)
+	})
+	return o.records().QueryContext(ctx, query, args...)
+}
+
+func (o *PostgresOperator) QualifiedTableName(table string) string {
+	schema, name := splitTableName(table)
+	return o.QuoteIdentifier(schema) + "." + o.QuoteIdentifier(name)
+}
+
+func (o *PostgresOperator) QuoteIdentifier(name string) string {
+	return pq.QuoteIdentifier(name)
+}
+
+func (o *PostgresOperator) SQLLiteral(val any, ty *sql.ColumnType) (string, error) {
+	if val == nil {
+		return "NULL", nil
+	}
+
+	s, err := o.FormatData(val, ty)
+	if err != nil {
+		return "", err
+	}
+
+	switch o.ColumnKind(ty) {
+	case ColumnKindInteger, ColumnKindFloat, ColumnKindDecimal:
+		// NaN and Infinity are only accepted as quoted literals.
+		if isNumberLiteral(s) {
+			return s, nil
+		}
+	case ColumnKindBool:
+		return strings.ToUpper(s), nil
+	}
+	// every other type, including bytea in its \x form, is read back
+	// from its text representation.
+	return quoteSQLString(s), nil
+}
+
+func (o *PostgresOperator) IdentityInsert(ctx context.Context, table string) (on, off string, err error) {
+	// PostgreSQL accepts explicit values for serial and identity columns.
+	return "", "", nil
+}
@@ -0,0 +1,446 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"testing"
+
+	_ "github.com/lib/pq"
+)
+
+var postgresTestOption = &Option{
+	DBType:        DBTypePostgres,
+	Host:          "127.0.0.1",
+	Port:          5432,
+	Database:      "dummy_database",
+	Schema:        "dummy_schema",
+	Schemas:       []string{"dummy_schema"},
+	User:          "postgres",
+	Password:      "postgresPassword1234",
+	SSLMode:       "disable",
+	OutDir:        "",
+	NullRepresent: "NULL",
+}
+
+func execPostgresTestSQL(database, query string) {
+	connString := fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=disable",
+		postgresTestOption.User, postgresTestOption.Password, postgresTestOption.Host, postgresTestOption.Port, database)
+
+	db, err := sql.Open("postgres", connString)
+	if err != nil {
+		log.Fatal("Error: Failed to connect to the database", err)
+	}
+	defer db.Close()
+
+	_, err = db.Exec(query)
+	if err != nil {
+		log.Fatal("Error: Failed to Exec", err)
+	}
+}
+
+func createTestDatabaseAndSchemaPostgres() {
+	execPostgresTestSQL("postgres", `DROP DATABASE IF EXISTS dummy_database WITH (FORCE)`)
+	execPostgresTestSQL("postgres", `CREATE DATABASE dummy_database`)
+	execPostgresTestSQL("dummy_database", `CREATE SCHEMA IF NOT EXISTS dummy_schema`)
+}
+
+func TestPostgresIntColumn(t *testing.T) {
+	skipIfShort(t)
+
+	// Create table for test
+	execPostgresTestSQL("dummy_database", `
+		DROP TABLE IF EXISTS dummy_schema.test_int_column_table;
+		CREATE TABLE dummy_schema.test_int_column_table (
+			int2_col SMALLINT NOT NULL,
+			int4_col INTEGER NOT NULL PRIMARY KEY,
+			int8_col BIGINT NOT NULL
+		);
+	`)
+	// Insert test data
+	execPostgresTestSQL("dummy_database", `
+		INSERT INTO dummy_schema.test_int_column_table VALUES (-32768, -2147483648, -9223372036854775808);
+		INSERT INTO dummy_schema.test_int_column_table VALUES (0, 0, 0);
+		INSERT INTO dummy_schema.test_int_column_table VALUES (32767, 2147483647, 9223372036854775807);
+	`)
+
+	postgresTestOption.OutDir = "testoutdir/postgres"
+	commandOption = postgresTestOption
+	exec(context.Background())
+
+	AssertCompareFiles(t, "testoutdir/postgres/test_int_column_table.csv", "testdata/postgres/test_int_column_table.csv")
+}
+
+func TestPostgresNumericColumn(t *testing.T) {
+	skipIfShort(t)
+
+	// Create table for test
+	execPostgresTestSQL("dummy_database", `
+		DROP TABLE IF EXISTS dummy_schema.test_numeric_column_table;
+		CREATE TABLE dummy_schema.test_numeric_column_table (
+			numeric_col NUMERIC(15, 3) NOT NULL PRIMARY KEY
+		);
+	`)
+	// Insert test data
+	execPostgresTestSQL("dummy_database", `
+		INSERT INTO dummy_schema.test_numeric_column_table VALUES (-999999999999.999);
+		INSERT INTO dummy_schema.test_numeric_column_table VALUES (0);
+		INSERT INTO dummy_schema.test_numeric_column_table VALUES (999999999999.999);
+	`)
+
+	postgresTestOption.OutDir = "testoutdir/postgres"
+	commandOption = postgresTestOption
+	exec(context.Background())
+
+	AssertCompareFiles(t, "testoutdir/postgres/test_numeric_column_table.csv", "testdata/postgres/test_numeric_column_table.csv")
+}
+
+func TestPostgresFloatColumn(t *testing.T) {
+	skipIfShort(t)
+
+	// Create table for test
+	execPostgresTestSQL("dummy_database", `
+		DROP TABLE IF EXISTS dummy_schema.test_float_column_table;
+		CREATE TABLE dummy_schema.test_float_column_table (
+			id INTEGER NOT NULL PRIMARY KEY,
+			real_col REAL,
+			double_col DOUBLE PRECISION
+		);
+	`)
+	// Insert test data
+	execPostgresTestSQL("dummy_database", `
+		INSERT INTO dummy_schema.test_float_column_table VALUES (1, -3.14, -1.79E+308);
+		INSERT INTO dummy_schema.test_float_column_table VALUES (2, 0, 0);
+		INSERT INTO dummy_schema.test_float_column_table VALUES (3, 3.14, 2.23E-308);
+		INSERT INTO dummy_schema.test_float_column_table VALUES (4, NULL, NULL);
+	`)
+
+	postgresTestOption.OutDir = "testoutdir/postgres"
+	commandOption = postgresTestOption
+	exec(context.Background())
+
+	AssertCompareFiles(t, "testoutdir/postgres/test_float_column_table.csv", "testdata/postgres/test_float_column_table.csv")
+}
+
+func TestPostgresTextColumn(t *testing.T) {
+	skipIfShort(t)
+
+	// Create table for test
+	execPostgresTestSQL("dummy_database", `
+		DROP TABLE IF EXISTS dummy_schema.test_text_column_table;
+		CREATE TABLE dummy_schema.test_text_column_table (
+			id INTEGER NOT NULL PRIMARY KEY,
+			text_col TEXT,
+			varchar_col VARCHAR(16),
+			char_col CHAR(5)
+		);
+	`)
+	// Insert test data
+	execPostgresTestSQL("dummy_database", `
+		INSERT INTO dummy_schema.test_text_column_table VALUES (1, 'abc', 'abc', 'abc');
+		INSERT INTO dummy_schema.test_text_column_table VALUES (2, '', '', '');
+		INSERT INTO dummy_schema.test_text_column_table VALUES (3, 'テスト', 'テスト', 'テスト');
+		INSERT INTO dummy_schema.test_text_column_table VALUES (4, 'TEST,STRING', 'a"b', 'a b');
+		INSERT INTO dummy_schema.test_text_column_table VALUES (5, NULL, NULL, NULL);
+	`)
+
+	postgresTestOption.OutDir = "testoutdir/postgres"
+	commandOption = postgresTestOption
+	exec(context.Background())
+
+	AssertCompareFiles(t, "testoutdir/postgres/test_text_column_table.csv", "testdata/postgres/test_text_column_table.csv")
+}
+
+func TestPostgresBoolColumn(t *testing.T) {
+	skipIfShort(t)
+
+	// Create table for test
+	execPostgresTestSQL("dummy_database", `
+		DROP TABLE IF EXISTS dummy_schema.test_bool_column_table;
+		CREATE TABLE dummy_schema.test_bool_column_table (
+			id INTEGER NOT NULL PRIMARY KEY,
+			bool_col BOOLEAN
+		);
+	`)
+	// Insert test data
+	execPostgresTestSQL("dummy_database", `
+		INSERT INTO dummy_schema.test_bool_column_table VALUES (1, true);
+		INSERT INTO dummy_schema.test_bool_column_table VALUES (2, false);
+		INSERT INTO dummy_schema.test_bool_column_table VALUES (3, NULL);
+	`)
+
+	postgresTestOption.OutDir = "testoutdir/postgres"
+	commandOption = postgresTestOption
+	exec(context.Background())
+
+	AssertCompareFiles(t, "testoutdir/postgres/test_bool_column_table.csv", "testdata/postgres/test_bool_column_table.csv")
+}
+
+func TestPostgresDateTimeColumn(t *testing.T) {
+	skipIfShort(t)
+
+	// Create table for test
+	execPostgresTestSQL("dummy_database", `
+		DROP TABLE IF EXISTS dummy_schema.test_datetime_column_table;
+		CREATE TABLE dummy_schema.test_datetime_column_table (
+			id INTEGER NOT NULL PRIMARY KEY,
+			date_col DATE,
+			timestamp_col TIMESTAMP,
+			timestamptz_col TIMESTAMPTZ
+		);
+	`)
+	// Insert test data
+	execPostgresTestSQL("dummy_database", `
+		SET TIME ZONE 'UTC';
+		INSERT INTO dummy_schema.test_datetime_column_table VALUES (1, '2025-03-22', '2025-03-22 21:54:24', '2025-03-22 21:54:24+00');
+		INSERT INTO dummy_schema.test_datetime_column_table VALUES (2, '2025-03-22', '2025-03-22 21:54:24.123456', '2025-03-22 21:54:24.123456+09');
+		INSERT INTO dummy_schema.test_datetime_column_table VALUES (3, NULL, NULL, NULL);
+	`)
+
+	postgresTestOption.OutDir = "testoutdir/postgres"
+	commandOption = postgresTestOption
+	exec(context.Background())
+
+	AssertCompareFiles(t, "testoutdir/postgres/test_datetime_column_table.csv", "testdata/postgres/test_datetime_column_table.csv")
+}
+
+func TestPostgresUuidColumn(t *testing.T) {
+	skipIfShort(t)
+
+	// Create table for test
+	execPostgresTestSQL("dummy_database", `
+		DROP TABLE IF EXISTS dummy_schema.test_uuid_column_table;
+		CREATE TABLE dummy_schema.test_uuid_column_table (
+			uuid_col UUID NOT NULL PRIMARY KEY
+		);
+	`)
+	// Insert test data
+	execPostgresTestSQL("dummy_database", `
+		INSERT INTO dummy_schema.test_uuid_column_table VALUES ('0E984725-C51C-4BF4-9960-E1C80E27ABA0');
+		INSERT INTO dummy_schema.test_uuid_column_table VALUES ('4487A153-A228-4287-900C-FA2EF942B4EB');
+	`)
+
+	postgresTestOption.OutDir = "testoutdir/postgres"
+	commandOption = postgresTestOption
+	exec(context.Background())
+
+	AssertCompareFiles(t, "testoutdir/postgres/test_uuid_column_table.csv", "testdata/postgres/test_uuid_column_table.csv")
+}
+
+func TestPostgresJsonColumn(t *testing.T) {
+	skipIfShort(t)
+
+	// Create table for test
+	execPostgresTestSQL("dummy_database", `
+		DROP TABLE IF EXISTS dummy_schema.test_json_column_table;
+		CREATE TABLE dummy_schema.test_json_column_table (
+			id INTEGER NOT NULL PRIMARY KEY,
+			json_col JSON,
+			jsonb_col JSONB
+		);
+	`)
+	// Insert test data
+	execPostgresTestSQL("dummy_database", `
+		INSERT INTO dummy_schema.test_json_column_table VALUES (1, '{"a":1}', '{"a":1}');
+		INSERT INTO dummy_schema.test_json_column_table VALUES (2, '[1,2,3]', '[1,2,3]');
+		INSERT INTO dummy_schema.test_json_column_table VALUES (3, NULL, NULL);
+	`)
+
+	postgresTestOption.OutDir = "testoutdir/postgres"
+	commandOption = postgresTestOption
+	exec(context.Background())
+
+	AssertCompareFiles(t, "testoutdir/postgres/test_json_column_table.csv", "testdata/postgres/test_json_column_table.csv")
+}
+
+func TestPostgresByteaColumn(t *testing.T) {
+	skipIfShort(t)
+
+	// Create table for test
+	execPostgresTestSQL("dummy_database", `
+		DROP TABLE IF EXISTS dummy_schema.test_bytea_column_table;
+		CREATE TABLE dummy_schema.test_bytea_column_table (
+			id INTEGER NOT NULL PRIMARY KEY,
+			bytea_col BYTEA
+		);
+	`)
+	// Insert test data
+	execPostgresTestSQL("dummy_database", `
+		INSERT INTO dummy_schema.test_bytea_column_table VALUES (1, '\xdeadbeef');
+		INSERT INTO dummy_schema.test_bytea_column_table VALUES (2, '\x');
+		INSERT INTO dummy_schema.test_bytea_column_table VALUES (3, NULL);
+	`)
+
+	postgresTestOption.OutDir = "testoutdir/postgres"
+	commandOption = postgresTestOption
+	exec(context.Background())
+
+	AssertCompareFiles(t, "testoutdir/postgres/test_bytea_column_table.csv", "testdata/postgres/test_bytea_column_table.csv")
+}
+
+func TestPostgresArrayColumn(t *testing.T) {
+	skipIfShort(t)
+
+	// Create table for test
+	execPostgresTestSQL("dummy_database", `
+		DROP TABLE IF EXISTS dummy_schema.test_array_column_table;
+		CREATE TABLE dummy_schema.test_array_column_table (
+			id INTEGER NOT NULL PRIMARY KEY,
+			int_array_col INTEGER[],
+			text_array_col TEXT[]
+		);
+	`)
+	// Insert test data
+	execPostgresTestSQL("dummy_database", `
+		INSERT INTO dummy_schema.test_array_column_table VALUES (1, '{1,2,3}', '{"a b",c}');
+		INSERT INTO dummy_schema.test_array_column_table VALUES (2, '{}', '{}');
+		INSERT INTO dummy_schema.test_array_column_table VALUES (3, NULL, NULL);
+	`)
+
+	postgresTestOption.OutDir = "testoutdir/postgres"
+	commandOption = postgresTestOption
+	exec(context.Background())
+
+	AssertCompareFiles(t, "testoutdir/postgres/test_array_column_table.csv", "testdata/postgres/test_array_column_table.csv")
+}
+
+func TestPostgresIntervalColumn(t *testing.T) {
+	skipIfShort(t)
+
+	// Create table for test
+	execPostgresTestSQL("dummy_database", `
+		DROP TABLE IF EXISTS dummy_schema.test_interval_column_table;
+		CREATE TABLE dummy_schema.test_interval_column_table (
+			id INTEGER NOT NULL PRIMARY KEY,
+			interval_col INTERVAL
+		);
+	`)
+	// Insert test data
+	execPostgresTestSQL("dummy_database", `
+		INSERT INTO dummy_schema.test_interval_column_table VALUES (1, '1 year 2 months 3 days 04:05:06');
+		INSERT INTO dummy_schema.test_interval_column_table VALUES (2, '-1 day');
+		INSERT INTO dummy_schema.test_interval_column_table VALUES (3, NULL);
+	`)
+
+	postgresTestOption.OutDir = "testoutdir/postgres"
+	commandOption = postgresTestOption
+	exec(context.Background())
+
+	AssertCompareFiles(t, "testoutdir/postgres/test_interval_column_table.csv", "testdata/postgres/test_interval_column_table.csv")
+}
+
+func TestPostgresUnsupportedColumnOutput(t *testing.T) {
+	skipIfShort(t)
+
+	// Create table for test
+	execPostgresTestSQL("dummy_database", `
+		DROP TABLE IF EXISTS dummy_schema.test_unsupported_column_output;
+		CREATE TABLE dummy_schema.test_unsupported_column_output (
+			col1 INTEGER NOT NULL PRIMARY KEY,
+			unsupported_col POINT,
+			col2 VARCHAR(32) NOT NULL
+		);
+	`)
+	// Insert test data
+	execPostgresTestSQL("dummy_database", `
+		INSERT INTO dummy_schema.test_unsupported_column_output VALUES (1, '(1,2)', 'test row 1');
+		INSERT INTO dummy_schema.test_unsupported_column_output VALUES (2, NULL, 'test row 2');
+	`)
+
+	postgresTestOption.OutDir = "testoutdir/postgres"
+	commandOption = postgresTestOption
+	exec(context.Background())
+
+	AssertCompareFiles(t, "testoutdir/postgres/test_unsupported_column_output.csv", "testdata/postgres/test_unsupported_column_output.csv")
+}
+
+func TestPostgresUnsupportedColumnPolicy(t *testing.T) {
+	skipIfShort(t)
+
+	// Create table for test
+	execPostgresTestSQL("dummy_database", `
+		DROP TABLE IF EXISTS dummy_schema.test_unsupported_column_policy;
+		CREATE TABLE dummy_schema.test_unsupported_column_policy (
+			col1 INTEGER NOT NULL PRIMARY KEY,
+			unsupported_col POINT,
+			col2 VARCHAR(32) NOT NULL
+		);
+	`)
+	// Insert test data
+	execPostgresTestSQL("dummy_database", `
+		INSERT INTO dummy_schema.test_unsupported_column_policy VALUES (1, '(1,2)', 'test row 1');
+		INSERT INTO dummy_schema.test_unsupported_column_policy VALUES (2, NULL, 'test row 2');
+	`)
+
+	postgresTestOption.ParsedTableNames = []string{"test_unsupported_column_policy"}
+	defer func() {
+		postgresTestOption.ParsedTableNames = nil
+		postgresTestOption.UnsupportedPolicy = ""
+	}()
+	commandOption = postgresTestOption
+
+	for _, policy := range []string{UnsupportedPolicySkipColumn, UnsupportedPolicyCast} {
+		postgresTestOption.OutDir = "testoutdir/postgres/unsupported_" + policy
+		postgresTestOption.UnsupportedPolicy = policy
+		exec(context.Background())
+
+		AssertCompareFiles(t, "testoutdir/postgres/unsupported_"+policy+"/test_unsupported_column_policy.csv", "testdata/postgres/test_unsupported_column_"+policy+".csv")
+	}
+
+	postgresTestOption.OutDir = "testoutdir/postgres/unsupported_error"
+	postgresTestOption.UnsupportedPolicy = UnsupportedPolicyError
+	summary := exec(context.Background())
+
+	want := "unsupported column types: unsupported_col (POINT)"
+	if r := summary.Results[0]; r.Err == nil || r.Err.Error() != want {
+		t.Errorf("want error: '%s', but got '%v'", want, r.Err)
+	}
+	if _, err := os.Stat("testoutdir/postgres/unsupported_error/test_unsupported_column_policy.csv"); !os.IsNotExist(err) {
+		t.Errorf("want no output file, but got '%v'", err)
+	}
+}
+
+func TestPostgresMultipleSchemas(t *testing.T) {
+	skipIfShort(t)
+
+	// Create tables of the same name in two schemas
+	execPostgresTestSQL("dummy_database", `
+		CREATE SCHEMA IF NOT EXISTS dummy_sales;
+		DROP TABLE IF EXISTS dummy_schema.test_schema_table;
+		DROP TABLE IF EXISTS dummy_sales.test_schema_table;
+		CREATE TABLE dummy_schema.test_schema_table (col1 INTEGER NOT NULL PRIMARY KEY, col2 VARCHAR(32) NOT NULL);
+		CREATE TABLE dummy_sales.test_schema_table (col1 INTEGER NOT NULL PRIMARY KEY, col2 VARCHAR(32) NOT NULL);
+	`)
+	// Insert test data
+	execPostgresTestSQL("dummy_database", `
+		INSERT INTO dummy_schema.test_schema_table VALUES (1, 'dummy_schema row');
+		INSERT INTO dummy_sales.test_schema_table VALUES (1, 'dummy_sales row');
+	`)
+
+	postgresTestOption.Schemas = []string{"dummy_schema", "dummy_sales"}
+	postgresTestOption.Includes = []string{"*.test_schema_table"}
+	postgresTestOption.ParsedIncludes, _ = parseTablePatterns(postgresTestOption.Includes)
+	defer func() {
+		postgresTestOption.Schemas = nil
+		postgresTestOption.Includes = nil
+		postgresTestOption.ParsedIncludes = nil
+		postgresTestOption.SchemaLayout = ""
+	}()
+	commandOption = postgresTestOption
+
+	postgresTestOption.OutDir = "testoutdir/postgres/schemas_dir"
+	postgresTestOption.SchemaLayout = SchemaLayoutDirectory
+	exec(context.Background())
+
+	AssertCompareFiles(t, "testoutdir/postgres/schemas_dir/dummy_schema/test_schema_table.csv", "testdata/postgres/test_schema_table_dummy_schema.csv")
+	AssertCompareFiles(t, "testoutdir/postgres/schemas_dir/dummy_sales/test_schema_table.csv", "testdata/postgres/test_schema_table_dummy_sales.csv")
+
+	postgresTestOption.OutDir = "testoutdir/postgres/schemas_prefix"
+	postgresTestOption.SchemaLayout = SchemaLayoutPrefix
+	exec(context.Background())
+
+	AssertCompareFiles(t, "testoutdir/postgres/schemas_prefix/dummy_schema.test_schema_table.csv", "testdata/postgres/test_schema_table_dummy_schema.csv")
+	AssertCompareFiles(t, "testoutdir/postgres/schemas_prefix/dummy_sales.test_schema_table.csv", "testdata/postgres/test_schema_table_dummy_sales.csv")
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// QueryFileExtension is the extension of the query files of -query-dir.
+const QueryFileExtension = ".sql"
+
+// NamedQuery is a query of -q or -query-dir, exported to <name>.csv like
+// a table.
+type NamedQuery struct {
+	Name string `json:"name"`
+	SQL  string `json:"sql"`
+}
+
+// queryMode reports whether queries are exported instead of tables.
+func queryMode() bool {
+	return len(commandOption.Queries) > 0
+}
+
+// findQuery returns the query named name.
+func findQuery(name string) (NamedQuery, bool) {
+	for _, q := range commandOption.Queries {
+		if q.Name == name {
+			return q, true
+		}
+	}
+	return NamedQuery{}, false
+}
+
+// queryNames returns the names of the queries to export, which take the
+// place of the tables in query mode.
+func queryNames() ([]string, map[string]string) {
+	var names []string
+	kinds := make(map[string]string)
+	for _, q := range commandOption.Queries {
+		names = append(names, q.Name)
+		kinds[q.Name] = ObjectKindQuery
+	}
+	return names, kinds
+}
+
+// parseQueryOption parses a query of -q written as <name>=<query>.
+func parseQueryOption(opstr string) (NamedQuery, error) {
+	name, query, ok := strings.Cut(opstr, "=")
+	name, query = strings.TrimSpace(name), strings.TrimSpace(query)
+	if !ok || !validQueryName(name) || query == "" {
+		return NamedQuery{}, fmt.Errorf("error: invalid query '%s' (-q). use <name>=<query>\n", opstr)
+	}
+	return NamedQuery{Name: name, SQL: query}, nil
+}
+
+// validQueryName reports whether name can be the name of an output file.
+func validQueryName(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.ContainsAny(name, `/\`)
+}
+
+// readQueryDir reads the queries of -query-dir, one per .sql file named
+// after the file.
+func readQueryDir(dir string) ([]NamedQuery, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var queries []NamedQuery
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != QueryFileExtension {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		queries = append(queries, NamedQuery{
+			Name: strings.TrimSuffix(entry.Name(), QueryFileExtension),
+			SQL:  strings.TrimSpace(string(data)),
+		})
+	}
+	return queries, nil
+}
+
+// parseQueryOptions returns the queries of -q followed by those of
+// -query-dir.
+func parseQueryOptions(opstrs []string, dir string) ([]NamedQuery, error) {
+	var queries []NamedQuery
+	for _, opstr := range opstrs {
+		q, err := parseQueryOption(opstr)
+		if err != nil {
+			return nil, err
+		}
+		queries = append(queries, q)
+	}
+
+	if dir != "" {
+		dirQueries, err := readQueryDir(dir)
+		if err != nil {
+			return nil, fmt.Errorf("error: cannot read the queries (-query-dir %s). %s\n", dir, err)
+		}
+		if len(dirQueries) == 0 {
+			return nil, fmt.Errorf("error: no %s files in the query directory (-query-dir %s)\n", QueryFileExtension, dir)
+		}
+		queries = append(queries, dirQueries...)
+	}
+
+	names := make(map[string]bool)
+	for _, q := range queries {
+		if names[q.Name] {
+			return nil, fmt.Errorf("error: duplicate query name '%s' (-q, -query-dir)\n", q.Name)
+		}
+		names[q.Name] = true
+	}
+	return queries, nil
+}
+
+// parseParamOptions parses the named parameters of -param written as
+// <name>=<value>.
+func parseParamOptions(opstrs []string) (map[string]string, error) {
+	params := make(map[string]string)
+	for _, opstr := range opstrs {
+		name, value, ok := strings.Cut(opstr, "=")
+		if !ok || !validParamName(name) {
+			return nil, fmt.Errorf("error: invalid parameter '%s' (-param). use <name>=<value>\n", opstr)
+		}
+		params[name] = value
+	}
+	return params, nil
+}
+
+// bindParams replaces the named parameters :name of query with the bind
+// parameter syntax returned by placeholder for the n-th argument. Names
+// in string literals, quoted identifiers and comments, and the :: casts of
+// PostgreSQL, are left alone.
+func bindParams(query string, params map[string]string, placeholder func(n int) string) (string, []any, error) {
+	var b strings.Builder
+	var args []any
+	for i := 0; i < len(query); {
+		c := query[i]
+		end := i + 1
+		switch {
+		case c == '\'' || c == '"' || c == '`' || c == '[':
+			closing := c
+			if c == '[' {
+				closing = ']'
+			}
+			end = len(query)
+			if n := strings.IndexByte(query[i+1:], closing); n >= 0 {
+				end = i + 1 + n + 1
+			}
+		case strings.HasPrefix(query[i:], "--"):
+			end = len(query)
+			if n := strings.IndexByte(query[i:], '\n'); n >= 0 {
+				end = i + n
+			}
+		case strings.HasPrefix(query[i:], "/*"):
+			end = len(query)
+			if n := strings.Index(query[i+2:], "*/"); n >= 0 {
+				end = i + 2 + n + 2
+			}
+		case strings.HasPrefix(query[i:], "::"):
+			end = i + 2
+		case c == ':' && i+1 < len(query) && isParamStart(query[i+1]):
+			end = i + 2
+			for end < len(query) && isParamChar(query[end]) {
+				end++
+			}
+			name := query[i+1 : end]
+			value, ok := params[name]
+			if !ok {
+				return "", nil, fmt.Errorf("parameter :%s is not given (-param)", name)
+			}
+			args = append(args, value)
+			b.WriteString(placeholder(len(args)))
+			i = end
+			continue
+		}
+		b.WriteString(query[i:end])
+		i = end
+	}
+	return b.String(), args, nil
+}
+
+func validParamName(name string) bool {
+	if name == "" || !isParamStart(name[0]) {
+		return false
+	}
+	for i := 1; i < len(name); i++ {
+		if !isParamChar(name[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isParamStart(c byte) bool {
+	return c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+func isParamChar(c byte) bool {
+	return isParamStart(c) || ('0' <= c && c <= '9')
+}
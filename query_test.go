@@ -0,0 +1,79 @@
+package main
+
+import (
+	"io"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestBindParams(t *testing.T) {
+	params := map[string]string{"since": "2024-01-01", "status": "open"}
+	question := func(n int) string { return "?" }
+
+	tests := []struct {
+		query string
+		want  string
+		args  []any
+	}{
+		{"SELECT * FROM orders WHERE created_at >= :since AND status = :status", "SELECT * FROM orders WHERE created_at >= ? AND status = ?", []any{"2024-01-01", "open"}},
+		{"SELECT ':since', \":status\", [:since] FROM orders", "SELECT ':since', \":status\", [:since] FROM orders", nil},
+		{"SELECT id::text FROM orders -- :since\nWHERE status = :status /* :since */", "SELECT id::text FROM orders -- :since\nWHERE status = ? /* :since */", []any{"open"}},
+		{"SELECT 'it''s' FROM orders WHERE a = :status OR b = :status", "SELECT 'it''s' FROM orders WHERE a = ? OR b = ?", []any{"open", "open"}},
+	}
+
+	for _, tt := range tests {
+		got, args, err := bindParams(tt.query, params, question)
+		if err != nil {
+			t.Fatalf("want error: 'nil', but got '%s'", err)
+		}
+		if got != tt.want || !reflect.DeepEqual(args, tt.args) {
+			t.Errorf("want: %q %v, but got %q %v", tt.want, tt.args, got, args)
+		}
+	}
+
+	if _, _, err := bindParams("SELECT * FROM orders WHERE id = :id", params, question); err == nil {
+		t.Errorf("want error for a parameter not given, but got nil")
+	}
+}
+
+func TestQueryOption(t *testing.T) {
+	dir := "testoutdir/queries"
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dir+"/monthly_sales.sql", []byte("SELECT 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dir+"/README.txt", []byte("not a query"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	args := []string{"db-puke", "sqlite", "-d", "testdata/sqlite/test_integer_column_table.csv"}
+	option, err := parseArgs(append(args, "-q", "active=SELECT * FROM users WHERE a = b", "-query-dir", dir, "-param", "since=2024-01-01"), io.Discard)
+	if err != nil {
+		t.Fatalf("want error: 'nil', but got '%s'", err)
+	}
+	want := []NamedQuery{{"active", "SELECT * FROM users WHERE a = b"}, {"monthly_sales", "SELECT 1"}}
+	if !reflect.DeepEqual(option.Queries, want) {
+		t.Errorf("want: %v, but got %v", want, option.Queries)
+	}
+	if want := map[string]string{"since": "2024-01-01"}; !reflect.DeepEqual(option.Params, want) {
+		t.Errorf("want: %v, but got %v", want, option.Params)
+	}
+
+	invalid := [][]string{
+		{"-q", "SELECT 1"},
+		{"-q", "../x=SELECT 1"},
+		{"-q", "a=SELECT 1", "-q", "a=SELECT 2"},
+		{"-q", "a=SELECT 1", "-t", "users"},
+		{"-q", "a=SELECT 1", "-param", "1x=2"},
+		{"-q", "a=SELECT 1", "-unsupported", "error"},
+		{"-query-dir", "testoutdir/missing"},
+	}
+	for _, opts := range invalid {
+		if _, err := parseArgs(append(append([]string{}, args...), opts...), io.Discard); err == nil {
+			t.Errorf("want error for %v, but got nil", opts)
+		}
+	}
+}
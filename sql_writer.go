@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"io"
+	"strings"
+)
+
+// SQLWriter writes records as an INSERT script that can be replayed
+// against a database of the same type. Rows are grouped into multi-row
+// INSERT statements of commandOption.SQLBatchSize rows.
+type SQLWriter struct {
+	operator    DBPukeOperator
+	table       string
+	columnTypes []*sql.ColumnType
+	columns     string
+	batchSize   int
+	batch       []string
+	identityOn  string
+	identityOff string
+	writer      *bufio.Writer
+}
+
+func NewSQLWriter(ctx context.Context, operator DBPukeOperator, table string, columnTypes []*sql.ColumnType, w io.Writer) (*SQLWriter, error) {
+	batch_size := commandOption.SQLBatchSize
+	if batch_size < 1 {
+		batch_size = DefaultSQLBatchSize
+	}
+
+	var on, off string
+	if commandOption.IdentityInsert {
+		var err error
+		on, off, err = operator.IdentityInsert(ctx, table)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &SQLWriter{
+		operator:    operator,
+		table:       operator.QualifiedTableName(table),
+		columnTypes: columnTypes,
+		batchSize:   batch_size,
+		batch:       make([]string, 0, batch_size),
+		identityOn:  on,
+		identityOff: off,
+		writer:      bufio.NewWriter(w),
+	}, nil
+}
+
+func (w *SQLWriter) WriteHeader(columns []string) error {
+	quoted := make([]string, len(columns))
+	for i, c := range columns {
+		quoted[i] = w.operator.QuoteIdentifier(c)
+	}
+	w.columns = strings.Join(quoted, ", ")
+
+	if w.identityOn != "" {
+		if _, err := w.writer.WriteString(w.identityOn + "\n"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w *SQLWriter) WriteRecord(record []string, values []any) error {
+	literals := make([]string, len(values))
+	for i, v := range values {
+		lit, err := w.operator.SQLLiteral(v, w.columnTypes[i])
+		if err != nil {
+			return err
+		}
+		literals[i] = lit
+	}
+	w.batch = append(w.batch, "("+strings.Join(literals, ", ")+")")
+
+	if len(w.batch) >= w.batchSize {
+		return w.writeBatch()
+	}
+	return nil
+}
+
+func (w *SQLWriter) Flush() error {
+	if err := w.writeBatch(); err != nil {
+		return err
+	}
+	if w.identityOff != "" {
+		if _, err := w.writer.WriteString(w.identityOff + "\n"); err != nil {
+			return err
+		}
+	}
+	return w.writer.Flush()
+}
+
+func (w *SQLWriter) writeBatch() error {
+	if len(w.batch) == 0 {
+		return nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString("INSERT INTO ")
+	sb.WriteString(w.table)
+	sb.WriteString(" (")
+	sb.WriteString(w.columns)
+	sb.WriteString(") VALUES\n")
+	sb.WriteString(strings.Join(w.batch, ",\n"))
+	sb.WriteString(";\n")
+	w.batch = w.batch[:0]
+
+	_, err := w.writer.WriteString(sb.String())
+	return err
+}
+
+// quoteSQLString encloses s in single quotes, doubling embedded quotes
+// as in standard SQL.
+func quoteSQLString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
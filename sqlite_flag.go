@@ -0,0 +1,42 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+const (
+	SqliteNoSpecifiedDatabaseMessage = "error: please specify the database file path (-d)\n"
+	SqliteDatabaseNotFoundMessage    = "error: database file is not found (-d)\n"
+)
+
+func sqliteUsageMessage(prg_name string) error {
+	return fmt.Errorf(`%s - database data exporter [version %s]
+
+Usage:
+  %s sqlite -d <database file path>
+
+Example:
+  sqlite(SQLite):
+    %s sqlite -d ./dummy_database.sqlite3
+
+See more:
+  '%s <database type> --help'
+`, prg_name, DBPukeVersion, prg_name, prg_name, prg_name)
+}
+
+func setSqliteFlag(option *Option, fs *flag.FlagSet) {
+	fs.StringVar(&option.Database, "d", "", "database file path")
+}
+
+func validateSqliteOption(option *Option) error {
+	if option.Database == "" {
+		return fmt.Errorf(SqliteNoSpecifiedDatabaseMessage)
+	}
+	if _, err := os.Stat(option.Database); err != nil {
+		return fmt.Errorf(SqliteDatabaseNotFoundMessage)
+	}
+
+	return nil
+}
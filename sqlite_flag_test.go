@@ -0,0 +1,71 @@
+package main
+
+import (
+	"io"
+	"testing"
+)
+
+func TestSqliteValidMinimumArgs(t *testing.T) {
+	option, err := parseArgs([]string{
+		"db-puke",
+		"sqlite",
+		"-d",
+		"testdata/sqlite/test_integer_column_table.csv",
+	}, io.Discard)
+	if err != nil {
+		t.Fatalf("call by valid args. want: nil, but got %s", err.Error())
+	}
+
+	if option.DBType != DBTypeSQLite {
+		t.Errorf("option.DBType want: %s, but got %s", DBTypeSQLite, option.DBType)
+	}
+
+	if option.Database != "testdata/sqlite/test_integer_column_table.csv" {
+		t.Errorf("option.Database want: %s, but got %s", "testdata/sqlite/test_integer_column_table.csv", option.Database)
+	}
+}
+
+func TestSqliteSubcommandHelpArgs(t *testing.T) {
+	_, err := parseArgs([]string{
+		"db-puke",
+		"sqlite",
+		"--help",
+	}, io.Discard)
+	if err == nil {
+		t.Errorf("call by --help args. want return error, but got nil")
+	}
+}
+
+func TestSqliteNoSpecifiedDatabase(t *testing.T) {
+	_, err := parseArgs([]string{
+		"db-puke",
+		"sqlite",
+		"-o",
+		"outdir",
+	}, io.Discard)
+
+	if err == nil {
+		t.Fatalf("call by invalid args. want error: '%s', but got nil", SqliteNoSpecifiedDatabaseMessage)
+	}
+
+	if err.Error() != SqliteNoSpecifiedDatabaseMessage {
+		t.Fatalf("call by invalid args. want error: '%s', but got '%s'", SqliteNoSpecifiedDatabaseMessage, err.Error())
+	}
+}
+
+func TestSqliteDatabaseNotFound(t *testing.T) {
+	_, err := parseArgs([]string{
+		"db-puke",
+		"sqlite",
+		"-d",
+		"testdata/sqlite/not_exists.sqlite3",
+	}, io.Discard)
+
+	if err == nil {
+		t.Fatalf("call by invalid args. want error: '%s', but got nil", SqliteDatabaseNotFoundMessage)
+	}
+
+	if err.Error() != SqliteDatabaseNotFoundMessage {
+		t.Fatalf("call by invalid args. want error: '%s', but got '%s'", SqliteDatabaseNotFoundMessage, err.Error())
+	}
+}
@@ -0,0 +1,335 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+const (
+	SqliteAffinityInteger = "INTEGER"
+	SqliteAffinityText    = "TEXT"
+	SqliteAffinityBlob    = "BLOB"
+	SqliteAffinityReal    = "REAL"
+	SqliteAffinityNumeric = "NUMERIC"
+)
+
+type SQLiteOperator struct {
+	connString string
+	db         *sql.DB
+	tx         *sql.Tx
+}
+
+func NewSQLiteOperator() *SQLiteOperator {
+	u := &url.URL{
+		Scheme:   "file",
+		Opaque:   commandOption.Database,
+		RawQuery: "mode=ro",
+	}
+	return &SQLiteOperator{connString: u.String()}
+}
+
+func (o *SQLiteOperator) DBOpen(ctx context.Context) error {
+	db, err := sql.Open("sqlite", o.connString)
+	if err != nil {
+		return err
+	}
+	o.db = db
+	db.SetMaxOpenConns(maxOpenConns())
+
+	err = db.PingContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (o *SQLiteOperator) DBClose() error {
+	return o.db.Close()
+}
+
+// BeginSnapshot opens a read transaction. SQLite transactions are
+// serializable, so every read sees the database as of the first one.
+func (o *SQLiteOperator) BeginSnapshot(ctx context.Context) error {
+	tx, err := o.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	o.tx = tx
+	return nil
+}
+
+// records returns where table data is read from, which is the snapshot
+// transaction while one is open.
+func (o *SQLiteOperator) records() queryer {
+	if o.tx != nil {
+		return o.tx
+	}
+	return o.db
+}
+
+func (o *SQLiteOperator) EndSnapshot() error {
+	if o.tx == nil {
+		return nil
+	}
+	err := o.tx.Commit()
+	o.tx = nil
+	return err
+}
+
+func (o *SQLiteOperator) GetTableNames(ctx context.Context) ([]TableObject, error) {
+	db := o.db
+
+	query := `
+		SELECT
+			name,
+			type
+		FROM
+			sqlite_master
+		WHERE
+			type IN ('table', 'view')
+		AND
+			name NOT LIKE 'sqlite\_%' ESCAPE '\'
+	`
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tables []TableObject
+	for rows.Next() {
+		var tname, tableType string
+
+		err := rows.Scan(&tname, &tableType)
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, TableObject{Name: tname, Kind: tableTypeKind(strings.ToUpper(tableType))})
+	}
+	return tables, nil
+}
+
+func (o *SQLiteOperator) QueryAllRecords(ctx context.Context, table string) (*sql.Rows, error) {
+	db := o.records()
+
+	query, args, err := tableQuery(o, selectList(o, table), table, func(n int) string {
+		return "?"
+	})
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
+
+func (o *SQLiteOperator) QueryRecords(ctx context.Context, query string, params map[string]string) (*sql.Rows, error) {
+	query, args, err := bindParams(query, params, func(n int) string {
+		return "?"
+	})
+	if err != nil {
+		return nil, err
+	}
+	return o.records().QueryContext(ctx, query, args...)
+}
+
+func (o *SQLiteOperator) ColumnTypes(ctx context.Context, table string) ([]*sql.ColumnType, error) {
+	return queryColumnTypes(ctx, o.db, fmt.Sprintf("SELECT * FROM %s WHERE 1 = 0", o.QualifiedTableName(table)))
+}
+
+func (o *SQLiteOperator) CastToText(expr string) string {
+	return fmt.Sprintf("CAST(%s AS TEXT)", expr)
+}
+
+func (o *SQLiteOperator) LimitQuery(query string, limit int64) string {
+	return fmt.Sprintf("%s LIMIT %d", query, limit)
+}
+
+func (o *SQLiteOperator) FormatData(val any, ty *sql.ColumnType) (string, error) {
+	if val == nil {
+		return commandOption.NullRepresent, nil
+	}
+	tyname := ty.DatabaseTypeName()
+
+	// SQLite stores each value with its own storage class, so the value is
+	// formatted by what was actually stored and the declared affinity only
+	// decides how ambiguous values are rendered.
+	switch v := val.(type) {
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case float64:
+		return fmt.Sprintf("%g", v), nil
+	case string:
+		return v, nil
+	case time.Time:
+		if tyname == "DATE" {
+			return v.Format("2006-01-02"), nil
+		}
+		return v.Format("2006-01-02 15:04:05.999999999"), nil
+	case []byte:
+		if sqliteAffinity(tyname) == SqliteAffinityBlob {
+			return "0x" + strings.ToUpper(hex.EncodeToString(v)), nil
+		}
+		return string(v), nil
+	}
+
+	return UnsupportedColumnTypeOutput, nil
+}
+
+func (o *SQLiteOperator) ColumnKind(ty *sql.ColumnType) ColumnKind {
+	tyname := strings.ToUpper(ty.DatabaseTypeName())
+
+	switch {
+	case strings.Contains(tyname, "BOOL"):
+		return ColumnKindBool
+	case tyname == "DATE":
+		return ColumnKindDate
+	case tyname == "DATETIME", tyname == "TIMESTAMP":
+		return ColumnKindDateTime
+	}
+
+	switch sqliteAffinity(tyname) {
+	case SqliteAffinityInteger:
+		return ColumnKindInteger
+	case SqliteAffinityReal:
+		return ColumnKindFloat
+	case SqliteAffinityNumeric:
+		return ColumnKindDecimal
+	case SqliteAffinityText:
+		return ColumnKindString
+	}
+	return ColumnKindBinary
+}
+
+func (o *SQLiteOperator) DecimalSize(ty *sql.ColumnType) (precision, scale int64, ok bool) {
+	return ty.DecimalSize()
+}
+
+func (o *SQLiteOperator) RowCountEstimates(ctx context.Context) (map[string]int64, error) {
+	// SQLite keeps no row counts unless ANALYZE has been run, so the
+	// statistics of the primary index are used when present.
+	var exists int
+	err := o.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'sqlite_stat1'").Scan(&exists)
+	if err != nil || exists == 0 {
+		return map[string]int64{}, err
+	}
+
+	query := `
+		SELECT
+			'main',
+			tbl,
+			MAX(CAST(stat AS INTEGER))
+		FROM
+			sqlite_stat1
+		GROUP BY
+			tbl
+	`
+	return queryRowCountEstimates(ctx, o.db, query)
+}
+
+func (o *SQLiteOperator) PrimaryKeyColumns(ctx context.Context, table string) ([]string, error) {
+	query := "SELECT name FROM pragma_table_info(?) WHERE pk > 0 ORDER BY pk"
+	return queryPrimaryKeyColumns(ctx, o.db, query, table)
+}
+
+func (o *SQLiteOperator) ColumnRange(ctx context.Context, table string, column string) (min, max any, err error) {
+	return queryColumnRange(ctx, o.records(), o.QualifiedTableName(table), o.QuoteIdentifier(column))
+}
+
+func (o *SQLiteOperator) QueryRecordsInRange(ctx context.Context, table string, column string, r KeyRange) (*sql.Rows, error) {
+	query, args := rangeQuery(selectList(o, table), o.QualifiedTableName(table), o.QuoteIdentifier(column), r, func(n int) string {
+		return "?"
+	})
+	for i, arg := range args {
+		// date/time values are stored as text, so bounds are compared as text too.
+		if t, ok := arg.(time.Time); ok {
+			args[i] = t.Format("2006-01-02 15:04:05")
+		}
+	}
+	return o.records().QueryContext(ctx, query, args...)
+}
+
+func (o *SQLiteOperator) QualifiedTableName(table string) string {
+	return quoteSqliteIdentifier(table)
+}
+
+func (o *SQLiteOperator) QuoteIdentifier(name string) string {
+	return quoteSqliteIdentifier(name)
+}
+
+func (o *SQLiteOperator) SQLLiteral(val any, ty *sql.ColumnType) (string, error) {
+	if val == nil {
+		return "NULL", nil
+	}
+
+	// literals follow the stored value so that the storage class survives
+	// the round trip.
+	switch v := val.(type) {
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case float64:
+		if math.IsInf(v, 0) {
+			// SQLite reads an out of range literal as infinity.
+			if v < 0 {
+				return "-1e999", nil
+			}
+			return "1e999", nil
+		}
+		s := strconv.FormatFloat(v, 'g', -1, 64)
+		if !strings.ContainsAny(s, ".e") {
+			// keep the REAL storage class for integral values.
+			s += ".0"
+		}
+		return s, nil
+	case []byte:
+		if sqliteAffinity(ty.DatabaseTypeName()) == SqliteAffinityBlob {
+			return "X'" + strings.ToUpper(hex.EncodeToString(v)) + "'", nil
+		}
+	}
+
+	s, err := o.FormatData(val, ty)
+	if err != nil {
+		return "", err
+	}
+	return quoteSQLString(s), nil
+}
+
+func (o *SQLiteOperator) IdentityInsert(ctx context.Context, table string) (on, off string, err error) {
+	// SQLite accepts explicit values for rowid and AUTOINCREMENT columns.
+	return "", "", nil
+}
+
+// sqliteAffinity determines the column affinity from a declared type
+// following the rules in https://www.sqlite.org/datatype3.html#determination_of_column_affinity
+func sqliteAffinity(decltype string) string {
+	t := strings.ToUpper(decltype)
+
+	switch {
+	case strings.Contains(t, "INT"):
+		return SqliteAffinityInteger
+	case strings.Contains(t, "CHAR"), strings.Contains(t, "CLOB"), strings.Contains(t, "TEXT"):
+		return SqliteAffinityText
+	case t == "", strings.Contains(t, "BLOB"):
+		return SqliteAffinityBlob
+	case strings.Contains(t, "REAL"), strings.Contains(t, "FLOA"), strings.Contains(t, "DOUB"):
+		return SqliteAffinityReal
+	}
+	return SqliteAffinityNumeric
+}
+
+func quoteSqliteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
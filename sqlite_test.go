@@ -0,0 +1,1426 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/reader"
+	"github.com/xuri/excelize/v2"
+	_ "modernc.org/sqlite"
+)
+
+var sqliteTestOption = &Option{
+	DBType:        DBTypeSQLite,
+	Database:      "testoutdir/dummy_database.sqlite3",
+	OutDir:        "",
+	NullRepresent: "NULL",
+	Delimiter:     ',',
+	Quote:         CSVQuoteMinimal,
+	SQLBatchSize:  DefaultSQLBatchSize,
+	Encoding:      EncodingUTF8,
+	EncodingError: EncodingErrorError,
+}
+
+func execSqliteTestSQL(query string) {
+	db, err := sql.Open("sqlite", sqliteTestOption.Database)
+	if err != nil {
+		log.Fatal("Error: Failed to connect to the database", err)
+	}
+	defer db.Close()
+
+	_, err = db.Exec(query)
+	if err != nil {
+		log.Fatal("Error: Failed to Exec", err)
+	}
+}
+
+func createTestDatabaseSqlite() {
+	if err := os.MkdirAll(filepath.Dir(sqliteTestOption.Database), 0755); err != nil {
+		log.Fatal("directory create failed.", err)
+	}
+	if err := os.RemoveAll(sqliteTestOption.Database); err != nil {
+		log.Fatal("database file remove failed.", err)
+	}
+}
+
+func TestSqliteIntegerColumn(t *testing.T) {
+	// Create table for test
+	execSqliteTestSQL(`
+		DROP TABLE IF EXISTS test_integer_column_table;
+		CREATE TABLE test_integer_column_table (
+			id INTEGER NOT NULL PRIMARY KEY,
+			int_col INT,
+			bigint_col BIGINT
+		);
+	`)
+	// Insert test data
+	execSqliteTestSQL(`
+		INSERT INTO test_integer_column_table VALUES (1, -2147483648, -9223372036854775808);
+		INSERT INTO test_integer_column_table VALUES (2, 0, 0);
+		INSERT INTO test_integer_column_table VALUES (3, 2147483647, 9223372036854775807);
+		INSERT INTO test_integer_column_table VALUES (4, '42', 'not a number');
+		INSERT INTO test_integer_column_table VALUES (5, NULL, NULL);
+	`)
+
+	sqliteTestOption.OutDir = "testoutdir/sqlite"
+	commandOption = sqliteTestOption
+	exec(context.Background())
+
+	AssertCompareFiles(t, "testoutdir/sqlite/test_integer_column_table.csv", "testdata/sqlite/test_integer_column_table.csv")
+}
+
+func TestSqliteRealColumn(t *testing.T) {
+	// Create table for test
+	execSqliteTestSQL(`
+		DROP TABLE IF EXISTS test_real_column_table;
+		CREATE TABLE test_real_column_table (
+			id INTEGER NOT NULL PRIMARY KEY,
+			real_col REAL,
+			double_col DOUBLE
+		);
+	`)
+	// Insert test data
+	execSqliteTestSQL(`
+		INSERT INTO test_real_column_table VALUES (1, -3.14, -1.5E+100);
+		INSERT INTO test_real_column_table VALUES (2, 0, 1);
+		INSERT INTO test_real_column_table VALUES (3, 3.14, 2.23E-308);
+		INSERT INTO test_real_column_table VALUES (4, NULL, NULL);
+	`)
+
+	sqliteTestOption.OutDir = "testoutdir/sqlite"
+	commandOption = sqliteTestOption
+	exec(context.Background())
+
+	AssertCompareFiles(t, "testoutdir/sqlite/test_real_column_table.csv", "testdata/sqlite/test_real_column_table.csv")
+}
+
+func TestSqliteNumericColumn(t *testing.T) {
+	// Create table for test
+	execSqliteTestSQL(`
+		DROP TABLE IF EXISTS test_numeric_column_table;
+		CREATE TABLE test_numeric_column_table (
+			id INTEGER NOT NULL PRIMARY KEY,
+			numeric_col NUMERIC,
+			decimal_col DECIMAL(10, 2),
+			bool_col BOOLEAN
+		);
+	`)
+	// Insert test data
+	execSqliteTestSQL(`
+		INSERT INTO test_numeric_column_table VALUES (1, 10, '12.50', true);
+		INSERT INTO test_numeric_column_table VALUES (2, 1.5, 0, false);
+		INSERT INTO test_numeric_column_table VALUES (3, 'abc', NULL, NULL);
+	`)
+
+	sqliteTestOption.OutDir = "testoutdir/sqlite"
+	commandOption = sqliteTestOption
+	exec(context.Background())
+
+	AssertCompareFiles(t, "testoutdir/sqlite/test_numeric_column_table.csv", "testdata/sqlite/test_numeric_column_table.csv")
+}
+
+func TestSqliteTextColumn(t *testing.T) {
+	// Create table for test
+	execSqliteTestSQL(`
+		DROP TABLE IF EXISTS test_text_column_table;
+		CREATE TABLE test_text_column_table (
+			id INTEGER NOT NULL PRIMARY KEY,
+			text_col TEXT,
+			varchar_col VARCHAR(10)
+		);
+	`)
+	// Insert test data
+	execSqliteTestSQL(`
+		INSERT INTO test_text_column_table VALUES (1, 'abc', 'abc');
+		INSERT INTO test_text_column_table VALUES (2, '', '');
+		INSERT INTO test_text_column_table VALUES (3, 'テスト', 'テスト');
+		INSERT INTO test_text_column_table VALUES (4, 'TEST,STRING', 123);
+		INSERT INTO test_text_column_table VALUES (5, NULL, NULL);
+	`)
+
+	sqliteTestOption.OutDir = "testoutdir/sqlite"
+	commandOption = sqliteTestOption
+	exec(context.Background())
+
+	AssertCompareFiles(t, "testoutdir/sqlite/test_text_column_table.csv", "testdata/sqlite/test_text_column_table.csv")
+}
+
+func TestSqliteBlobColumn(t *testing.T) {
+	// Create table for test
+	execSqliteTestSQL(`
+		DROP TABLE IF EXISTS test_blob_column_table;
+		CREATE TABLE test_blob_column_table (
+			id INTEGER NOT NULL PRIMARY KEY,
+			blob_col BLOB,
+			untyped_col
+		);
+	`)
+	// Insert test data
+	execSqliteTestSQL(`
+		INSERT INTO test_blob_column_table VALUES (1, x'DEADBEEF', 'text');
+		INSERT INTO test_blob_column_table VALUES (2, x'', 1);
+		INSERT INTO test_blob_column_table VALUES (3, NULL, NULL);
+	`)
+
+	sqliteTestOption.OutDir = "testoutdir/sqlite"
+	commandOption = sqliteTestOption
+	exec(context.Background())
+
+	AssertCompareFiles(t, "testoutdir/sqlite/test_blob_column_table.csv", "testdata/sqlite/test_blob_column_table.csv")
+}
+
+func TestSqliteDateTimeColumn(t *testing.T) {
+	// Create table for test
+	execSqliteTestSQL(`
+		DROP TABLE IF EXISTS test_datetime_column_table;
+		CREATE TABLE test_datetime_column_table (
+			id INTEGER NOT NULL PRIMARY KEY,
+			date_col DATE,
+			datetime_col DATETIME
+		);
+	`)
+	// Insert test data
+	execSqliteTestSQL(`
+		INSERT INTO test_datetime_column_table VALUES (1, '2025-03-22', '2025-03-22 21:54:24');
+		INSERT INTO test_datetime_column_table VALUES (2, '2025-03-22', '2025-03-22 21:54:24.123');
+		INSERT INTO test_datetime_column_table VALUES (3, 'unknown', 'unknown');
+		INSERT INTO test_datetime_column_table VALUES (4, NULL, NULL);
+	`)
+
+	sqliteTestOption.OutDir = "testoutdir/sqlite"
+	commandOption = sqliteTestOption
+	exec(context.Background())
+
+	AssertCompareFiles(t, "testoutdir/sqlite/test_datetime_column_table.csv", "testdata/sqlite/test_datetime_column_table.csv")
+}
+
+func TestSqliteCSVDialect(t *testing.T) {
+	// Create table for test
+	execSqliteTestSQL(`
+		DROP TABLE IF EXISTS test_csv_dialect;
+		CREATE TABLE test_csv_dialect (
+			id INTEGER NOT NULL PRIMARY KEY,
+			real_col REAL,
+			text_col TEXT
+		);
+	`)
+	// Insert test data
+	execSqliteTestSQL(`
+		INSERT INTO test_csv_dialect VALUES (1, 3.14, 'tab	"separated"');
+		INSERT INTO test_csv_dialect VALUES (2, NULL, '00123');
+	`)
+
+	sqliteTestOption.OutDir = "testoutdir/sqlite"
+	sqliteTestOption.Delimiter = '\t'
+	sqliteTestOption.Quote = CSVQuoteNonNumeric
+	sqliteTestOption.CRLF = true
+	sqliteTestOption.NoHeader = true
+	defer func() {
+		sqliteTestOption.Delimiter = ','
+		sqliteTestOption.Quote = CSVQuoteMinimal
+		sqliteTestOption.CRLF = false
+		sqliteTestOption.NoHeader = false
+	}()
+	commandOption = sqliteTestOption
+	exec(context.Background())
+
+	AssertCompareFiles(t, "testoutdir/sqlite/test_csv_dialect.csv", "testdata/sqlite/test_csv_dialect.csv")
+}
+
+func TestSqliteEUCJPOutput(t *testing.T) {
+	// Create table for test
+	execSqliteTestSQL(`
+		DROP TABLE IF EXISTS test_euc_jp_output;
+		CREATE TABLE test_euc_jp_output (
+			id INTEGER NOT NULL PRIMARY KEY,
+			text_col TEXT
+		);
+	`)
+	// Insert test data
+	execSqliteTestSQL(`
+		INSERT INTO test_euc_jp_output VALUES (1, '日本語');
+		INSERT INTO test_euc_jp_output VALUES (2, '絵文字😀');
+	`)
+
+	sqliteTestOption.OutDir = "testoutdir/sqlite"
+	sqliteTestOption.Encoding = EncodingEUCJP
+	sqliteTestOption.EncodingError = EncodingErrorHTML
+	defer func() {
+		sqliteTestOption.Encoding = EncodingUTF8
+		sqliteTestOption.EncodingError = EncodingErrorError
+	}()
+	commandOption = sqliteTestOption
+	exec(context.Background())
+
+	AssertCompareFiles(t, "testoutdir/sqlite/test_euc_jp_output.csv", "testdata/sqlite/test_euc_jp_output.csv")
+}
+
+func TestSqliteAtomicOutput(t *testing.T) {
+	// Create table for test
+	execSqliteTestSQL(`
+		DROP TABLE IF EXISTS test_atomic_output;
+		CREATE TABLE test_atomic_output (
+			id INTEGER NOT NULL PRIMARY KEY,
+			text_col TEXT
+		);
+	`)
+	// Insert test data
+	// the emoji cannot be encoded in EUC-JP, which fails the export after
+	// the first row has been written.
+	execSqliteTestSQL(`
+		INSERT INTO test_atomic_output VALUES (1, 'ok');
+		INSERT INTO test_atomic_output VALUES (2, '絵文字😀');
+	`)
+
+	outdir := "testoutdir/sqlite/atomic"
+	output := filepath.Join(outdir, "test_atomic_output.csv")
+	if err := os.MkdirAll(outdir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(output, []byte("previous export\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	sqliteTestOption.OutDir = outdir
+	sqliteTestOption.ParsedTableNames = []string{"test_atomic_output"}
+	sqliteTestOption.Encoding = EncodingEUCJP
+	defer func() {
+		sqliteTestOption.ParsedTableNames = nil
+		sqliteTestOption.Encoding = EncodingUTF8
+	}()
+	commandOption = sqliteTestOption
+	summary := exec(context.Background())
+
+	if summary.Failed() != 1 {
+		t.Errorf("want failed: 1, but got %d", summary.Failed())
+	}
+	if data, _ := os.ReadFile(output); string(data) != "previous export\n" {
+		t.Errorf("want the previous export kept, but got %q", data)
+	}
+
+	sqliteTestOption.Encoding = EncodingUTF8
+	summary = exec(context.Background())
+
+	if summary.Failed() != 0 {
+		t.Errorf("want failed: 0, but got %d", summary.Failed())
+	}
+	if data, _ := os.ReadFile(output); string(data) != "id,text_col\n1,ok\n2,絵文字😀\n" {
+		t.Errorf("want the new export, but got %q", data)
+	}
+
+	temps, _ := filepath.Glob(filepath.Join(outdir, ".*.tmp"))
+	if len(temps) != 0 {
+		t.Errorf("want temporary files removed, but got %v", temps)
+	}
+}
+
+func TestSqliteResume(t *testing.T) {
+	// Create table for test
+	// test_resume_b is missing in the first export, which fails it.
+	execSqliteTestSQL(`
+		DROP TABLE IF EXISTS test_resume_a;
+		DROP TABLE IF EXISTS test_resume_b;
+		CREATE TABLE test_resume_a (id INTEGER NOT NULL PRIMARY KEY);
+		INSERT INTO test_resume_a VALUES (1);
+	`)
+
+	outdir := "testoutdir/sqlite/resume"
+	RemoveTestOutputFile(outdir)
+	sqliteTestOption.OutDir = outdir
+	sqliteTestOption.ParsedTableNames = []string{"test_resume_a", "test_resume_b"}
+	defer func() {
+		sqliteTestOption.ParsedTableNames = nil
+		sqliteTestOption.Resume = false
+	}()
+	commandOption = sqliteTestOption
+	summary := exec(context.Background())
+
+	if summary.Failed() != 1 {
+		t.Fatalf("want failed: 1, but got %d", summary.Failed())
+	}
+	checkpoint, err := loadCheckpoint()
+	if err != nil || checkpoint == nil {
+		t.Fatalf("want the checkpoint, but got (%v, %v)", checkpoint, err)
+	}
+	entry, ok := checkpoint.Tables["test_resume_a"]
+	if !ok || entry.Rows != 1 || len(entry.Files) != 1 {
+		t.Fatalf("want test_resume_a with 1 row and 1 file, but got %+v", entry)
+	}
+	data, _ := os.ReadFile(filepath.Join(outdir, "test_resume_a.csv"))
+	if sum := sha256.Sum256(data); entry.Files[0].SHA256 != hex.EncodeToString(sum[:]) || entry.Files[0].Bytes != int64(len(data)) {
+		t.Errorf("want the size and the checksum of the file, but got %+v", entry.Files[0])
+	}
+	if _, ok := checkpoint.Tables["test_resume_b"]; ok {
+		t.Errorf("want the failed table not recorded")
+	}
+
+	// test_resume_a would have 2 rows if it was exported again.
+	execSqliteTestSQL(`
+		INSERT INTO test_resume_a VALUES (2);
+		CREATE TABLE test_resume_b (id INTEGER NOT NULL PRIMARY KEY);
+		INSERT INTO test_resume_b VALUES (1), (2), (3);
+	`)
+	sqliteTestOption.Resume = true
+	summary = exec(context.Background())
+
+	if r := summary.Results[0]; r.Status() != TableStatusSkipped || r.Rows != 1 {
+		t.Errorf("want: (skipped, 1), but got (%s, %d)", r.Status(), r.Rows)
+	}
+	if r := summary.Results[1]; r.Status() != TableStatusOK || r.Rows != 3 {
+		t.Errorf("want: (ok, 3), but got (%s, %d)", r.Status(), r.Rows)
+	}
+	AssertCompareFiles(t, filepath.Join(outdir, "test_resume_a.csv"), "testdata/sqlite/test_resume_a.csv")
+
+	// a missing file is exported again.
+	os.Remove(filepath.Join(outdir, "test_resume_a.csv"))
+	summary = exec(context.Background())
+
+	if r := summary.Results[0]; r.Status() != TableStatusOK || r.Rows != 2 {
+		t.Errorf("want: (ok, 2), but got (%s, %d)", r.Status(), r.Rows)
+	}
+	if r := summary.Results[1]; r.Status() != TableStatusSkipped {
+		t.Errorf("want: skipped, but got %s", r.Status())
+	}
+}
+
+func TestSqliteManifest(t *testing.T) {
+	// Create table for test
+	// test_manifest_missing does not exist, which fails it.
+	execSqliteTestSQL(`
+		DROP TABLE IF EXISTS test_manifest;
+		CREATE TABLE test_manifest (
+			id INTEGER NOT NULL PRIMARY KEY,
+			name TEXT,
+			price NUMERIC(10, 2) NOT NULL
+		);
+		INSERT INTO test_manifest VALUES (1, 'apple', 1.5), (2, NULL, 20);
+	`)
+
+	outdir := "testoutdir/sqlite/manifest"
+	RemoveTestOutputFile(outdir)
+	sqliteTestOption.OutDir = outdir
+	sqliteTestOption.ParsedTableNames = []string{"test_manifest", "test_manifest_missing"}
+	defer func() { sqliteTestOption.ParsedTableNames = nil }()
+	commandOption = sqliteTestOption
+	exec(context.Background())
+
+	data, err := os.ReadFile(filepath.Join(outdir, ManifestFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatal(err)
+	}
+
+	if manifest.Complete || manifest.Source.Type != "sqlite" || len(manifest.Tables) != 2 {
+		t.Fatalf("want an incomplete manifest of 2 sqlite tables, but got %+v", manifest)
+	}
+
+	table := manifest.Tables[0]
+	if table.Table != "test_manifest" || table.Status != TableStatusOK || table.Rows != 2 || len(table.Files) != 1 {
+		t.Fatalf("want test_manifest with 2 rows and 1 file, but got %+v", table)
+	}
+	file := table.Files[0]
+	output, _ := os.ReadFile(filepath.Join(outdir, file.Name))
+	if sum := sha256.Sum256(output); file.Name != "test_manifest.csv" || file.Bytes != int64(len(output)) || file.SHA256 != hex.EncodeToString(sum[:]) {
+		t.Errorf("want the name, the size and the checksum of the file, but got %+v", file)
+	}
+
+	wantColumns := []struct {
+		name, databaseType string
+	}{
+		{"id", "INTEGER"},
+		{"name", "TEXT"},
+		{"price", "NUMERIC(10, 2)"},
+	}
+	if len(table.Columns) != len(wantColumns) {
+		t.Fatalf("want columns: %d, but got %d", len(wantColumns), len(table.Columns))
+	}
+	for i, want := range wantColumns {
+		if c := table.Columns[i]; c.Name != want.name || c.DatabaseType != want.databaseType {
+			t.Errorf("want column %d: (%s, %s), but got (%s, %s)", i, want.name, want.databaseType, c.Name, c.DatabaseType)
+		}
+	}
+
+	missing := manifest.Tables[1]
+	if missing.Status != TableStatusFailed || missing.Error == "" || len(missing.Files) != 0 {
+		t.Errorf("want test_manifest_missing failed without files, but got %+v", missing)
+	}
+}
+
+func TestSqliteExportSummary(t *testing.T) {
+	// Create table for test
+	execSqliteTestSQL(`
+		DROP TABLE IF EXISTS test_export_summary;
+		CREATE TABLE test_export_summary (id INTEGER NOT NULL PRIMARY KEY);
+	`)
+	// Insert test data
+	execSqliteTestSQL(`
+		INSERT INTO test_export_summary VALUES (1), (2), (3);
+	`)
+
+	sqliteTestOption.OutDir = "testoutdir/sqlite"
+	sqliteTestOption.ParsedTableNames = []string{"test_export_summary", "test_export_summary_missing"}
+	defer func() { sqliteTestOption.ParsedTableNames = nil }()
+	commandOption = sqliteTestOption
+	summary := exec(context.Background())
+
+	if summary.Failed() != 1 {
+		t.Errorf("want failed: 1, but got %d", summary.Failed())
+	}
+	if len(summary.Results) != 2 {
+		t.Fatalf("want results: 2, but got %d", len(summary.Results))
+	}
+	if r := summary.Results[0]; r.Table != "test_export_summary" || r.Rows != 3 || r.Err != nil {
+		t.Errorf("want: (test_export_summary, 3, nil), but got (%s, %d, %v)", r.Table, r.Rows, r.Err)
+	}
+	if r := summary.Results[1]; r.Table != "test_export_summary_missing" || r.Err == nil || r.Err.Error() != "table does not exist" {
+		t.Errorf("want: (test_export_summary_missing, table does not exist), but got (%s, %v)", r.Table, r.Err)
+	}
+}
+
+func TestSqliteTableNameCase(t *testing.T) {
+	// Create table for test
+	execSqliteTestSQL(`
+		DROP TABLE IF EXISTS Test_Table_Name_Case;
+		CREATE TABLE Test_Table_Name_Case (id INTEGER NOT NULL PRIMARY KEY);
+	`)
+	// Insert test data
+	execSqliteTestSQL(`
+		INSERT INTO Test_Table_Name_Case VALUES (1), (2);
+	`)
+
+	sqliteTestOption.OutDir = "testoutdir/sqlite"
+	sqliteTestOption.ParsedTableNames = []string{"test_table_name_case"}
+	defer func() { sqliteTestOption.ParsedTableNames = nil }()
+	commandOption = sqliteTestOption
+	summary := exec(context.Background())
+
+	if len(summary.Results) != 1 {
+		t.Fatalf("want results: 1, but got %d", len(summary.Results))
+	}
+	if r := summary.Results[0]; r.Table != "Test_Table_Name_Case" || r.Rows != 2 || r.Err != nil {
+		t.Errorf("want: (Test_Table_Name_Case, 2, nil), but got (%s, %d, %v)", r.Table, r.Rows, r.Err)
+	}
+	if _, err := os.Stat("testoutdir/sqlite/Test_Table_Name_Case.csv"); err != nil {
+		t.Errorf("want the file named after the table, but got '%s'", err)
+	}
+}
+
+func TestSqliteTablePatterns(t *testing.T) {
+	// Create table for test
+	execSqliteTestSQL(`
+		DROP TABLE IF EXISTS test_pattern_a;
+		DROP TABLE IF EXISTS test_pattern_b;
+		DROP TABLE IF EXISTS test_pattern_a_bak;
+		CREATE TABLE test_pattern_a (id INTEGER NOT NULL PRIMARY KEY);
+		CREATE TABLE test_pattern_b (id INTEGER NOT NULL PRIMARY KEY);
+		CREATE TABLE test_pattern_a_bak (id INTEGER NOT NULL PRIMARY KEY);
+	`)
+
+	sqliteTestOption.OutDir = "testoutdir/sqlite/patterns"
+	sqliteTestOption.ParsedIncludes, _ = parseTablePatterns([]string{"test_pattern_*"})
+	sqliteTestOption.ParsedExcludes, _ = parseTablePatterns([]string{"*_bak"})
+	defer func() {
+		sqliteTestOption.ParsedIncludes = nil
+		sqliteTestOption.ParsedExcludes = nil
+	}()
+	commandOption = sqliteTestOption
+	summary := exec(context.Background())
+
+	var tables []string
+	for _, r := range summary.Results {
+		tables = append(tables, r.Table)
+	}
+	if want := []string{"test_pattern_a", "test_pattern_b"}; !reflect.DeepEqual(tables, want) {
+		t.Errorf("want: %v, but got %v", want, tables)
+	}
+}
+
+func TestSqliteViews(t *testing.T) {
+	// Create table for test
+	execSqliteTestSQL(`
+		DROP VIEW IF EXISTS test_view_expensive;
+		DROP TABLE IF EXISTS test_view_items;
+		CREATE TABLE test_view_items (id INTEGER NOT NULL PRIMARY KEY, price INTEGER NOT NULL);
+		INSERT INTO test_view_items VALUES (1, 100), (2, 2000);
+		CREATE VIEW test_view_expensive AS SELECT id, price FROM test_view_items WHERE price > 1000;
+	`)
+
+	outdir := "testoutdir/sqlite/views"
+	RemoveTestOutputFile(outdir)
+	sqliteTestOption.OutDir = outdir
+	sqliteTestOption.ParsedIncludes, _ = parseTablePatterns([]string{"test_view_*"})
+	defer func() {
+		sqliteTestOption.ParsedIncludes = nil
+		sqliteTestOption.ParsedTableNames = nil
+		sqliteTestOption.Views = false
+	}()
+	commandOption = sqliteTestOption
+
+	kinds := func(summary *ExportSummary) map[string]string {
+		got := make(map[string]string)
+		for _, r := range summary.Results {
+			got[r.Table] = r.Kind
+		}
+		return got
+	}
+
+	summary := exec(context.Background())
+	if want := map[string]string{"test_view_items": ObjectKindTable}; !reflect.DeepEqual(kinds(summary), want) {
+		t.Errorf("want: %v, but got %v", want, kinds(summary))
+	}
+
+	sqliteTestOption.Views = true
+	summary = exec(context.Background())
+	if want := map[string]string{"test_view_items": ObjectKindTable, "test_view_expensive": ObjectKindView}; !reflect.DeepEqual(kinds(summary), want) {
+		t.Errorf("want: %v, but got %v", want, kinds(summary))
+	}
+
+	data, err := os.ReadFile(filepath.Join(outdir, ManifestFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatal(err)
+	}
+	for _, table := range manifest.Tables {
+		if table.Table == "test_view_expensive" && (table.Kind != ObjectKindView || table.Rows != 1) {
+			t.Errorf("want the view with 1 row, but got %+v", table)
+		}
+	}
+
+	// a named view is exported without -views.
+	sqliteTestOption.Views = false
+	sqliteTestOption.ParsedTableNames = []string{"test_view_expensive"}
+	summary = exec(context.Background())
+	if r := summary.Results[0]; r.Err != nil || r.Kind != ObjectKindView {
+		t.Errorf("want the view exported, but got %+v", r)
+	}
+}
+
+func TestSqliteQueryExport(t *testing.T) {
+	// Create table for test
+	execSqliteTestSQL(`
+		DROP TABLE IF EXISTS test_query_orders;
+		DROP TABLE IF EXISTS test_query_customers;
+		CREATE TABLE test_query_customers (id INTEGER NOT NULL PRIMARY KEY, name TEXT NOT NULL);
+		CREATE TABLE test_query_orders (id INTEGER NOT NULL PRIMARY KEY, customer_id INTEGER NOT NULL, amount NUMERIC(10, 2) NOT NULL);
+		INSERT INTO test_query_customers VALUES (1, 'alice'), (2, 'bob');
+		INSERT INTO test_query_orders VALUES (1, 1, 10.5), (2, 2, 200), (3, 1, 30);
+	`)
+
+	outdir := "testoutdir/sqlite/query"
+	RemoveTestOutputFile(outdir)
+	sqliteTestOption.OutDir = outdir
+	sqliteTestOption.Queries = []NamedQuery{{
+		Name: "test_query_large_orders",
+		SQL:  "SELECT o.id, c.name, o.amount FROM test_query_orders o INNER JOIN test_query_customers c ON c.id = o.customer_id WHERE o.amount >= :min ORDER BY o.id",
+	}}
+	sqliteTestOption.Params = map[string]string{"min": "30"}
+	defer func() {
+		sqliteTestOption.Queries = nil
+		sqliteTestOption.Params = nil
+	}()
+	commandOption = sqliteTestOption
+	summary := exec(context.Background())
+
+	if r := summary.Results[0]; r.Err != nil || r.Table != "test_query_large_orders" || r.Kind != ObjectKindQuery || r.Rows != 2 {
+		t.Fatalf("want the query exported with 2 rows, but got %+v", r)
+	}
+	AssertCompareFiles(t, outdir+"/test_query_large_orders.csv", "testdata/sqlite/test_query_large_orders.csv")
+}
+
+func TestSqliteTableFilters(t *testing.T) {
+	// Create table for test
+	execSqliteTestSQL(`
+		DROP TABLE IF EXISTS test_filter_orders;
+		DROP TABLE IF EXISTS test_filter_users;
+		CREATE TABLE test_filter_orders (id INTEGER NOT NULL PRIMARY KEY, status TEXT NOT NULL);
+		CREATE TABLE test_filter_users (id INTEGER NOT NULL PRIMARY KEY);
+		INSERT INTO test_filter_orders VALUES (1, 'open'), (2, 'closed'), (3, 'open'), (4, 'open');
+		INSERT INTO test_filter_users VALUES (1), (2), (3);
+	`)
+
+	outdir := "testoutdir/sqlite/filters"
+	RemoveTestOutputFile(outdir)
+	sqliteTestOption.OutDir = outdir
+	sqliteTestOption.ParsedTableNames = []string{"test_filter_orders", "test_filter_users"}
+	sqliteTestOption.TableFilters = map[string]*TableFilter{
+		"test_filter_orders": {Where: "status = :status", OrderBy: []OrderColumn{{Name: "id", Desc: true}}, Limit: 2},
+	}
+	sqliteTestOption.Params = map[string]string{"status": "open"}
+	sqliteTestOption.Limit = 1
+	sqliteTestOption.Chunks = 2
+	defer func() {
+		sqliteTestOption.ParsedTableNames = nil
+		sqliteTestOption.TableFilters = nil
+		sqliteTestOption.Params = nil
+		sqliteTestOption.Limit = 0
+		sqliteTestOption.Chunks = 0
+	}()
+	commandOption = sqliteTestOption
+	exec(context.Background())
+
+	AssertCompareFiles(t, outdir+"/test_filter_orders.csv", "testdata/sqlite/test_filter_orders.csv")
+	AssertCompareFiles(t, outdir+"/test_filter_users.csv", "testdata/sqlite/test_filter_users.csv")
+}
+
+func TestSqliteScheduleLargestFirst(t *testing.T) {
+	// Create table for test
+	execSqliteTestSQL(`
+		DROP TABLE IF EXISTS test_schedule_small;
+		DROP TABLE IF EXISTS test_schedule_large;
+		DROP TABLE IF EXISTS test_schedule_medium;
+		CREATE TABLE test_schedule_small (id INTEGER NOT NULL PRIMARY KEY);
+		CREATE TABLE test_schedule_large (id INTEGER NOT NULL PRIMARY KEY);
+		CREATE TABLE test_schedule_medium (id INTEGER NOT NULL PRIMARY KEY);
+	`)
+	// Insert test data
+	execSqliteTestSQL(`
+		INSERT INTO test_schedule_small VALUES (1);
+		INSERT INTO test_schedule_large VALUES (1), (2), (3), (4), (5);
+		INSERT INTO test_schedule_medium VALUES (1), (2), (3);
+		ANALYZE;
+	`)
+
+	commandOption = sqliteTestOption
+	operator := NewSQLiteOperator()
+	if err := operator.DBOpen(context.Background()); err != nil {
+		t.Fatalf("open database failed: %v", err)
+	}
+	defer operator.DBClose()
+
+	tables := []string{"test_schedule_small", "test_schedule_large", "test_schedule_medium"}
+	got := scheduleTables(context.Background(), operator, tables)
+
+	want := []int{1, 2, 0}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want: %v, but got %v", want, got)
+	}
+}
+
+func TestSqliteChunkedExport(t *testing.T) {
+	// Create table for test
+	execSqliteTestSQL(`
+		DROP TABLE IF EXISTS test_chunked_export;
+		CREATE TABLE test_chunked_export (
+			id INTEGER NOT NULL PRIMARY KEY,
+			k INTEGER,
+			name TEXT
+		);
+	`)
+	// Insert test data
+	// k repeats the values the ranges are split on and has NULLs, which
+	// only the first chunk selects.
+	execSqliteTestSQL(`
+		INSERT INTO test_chunked_export VALUES (1, 100, 'max');
+		INSERT INTO test_chunked_export VALUES (2, NULL, 'null 1');
+		INSERT INTO test_chunked_export VALUES (3, -100, 'min');
+		INSERT INTO test_chunked_export VALUES (4, 0, 'bound 0');
+		INSERT INTO test_chunked_export VALUES (5, 0, 'bound 0 again');
+		INSERT INTO test_chunked_export VALUES (6, 50, 'bound 50');
+		INSERT INTO test_chunked_export VALUES (7, -50, 'bound -50');
+		INSERT INTO test_chunked_export VALUES (8, -51, 'below -50');
+		INSERT INTO test_chunked_export VALUES (9, 49, 'below 50');
+		INSERT INTO test_chunked_export VALUES (10, NULL, 'null 2');
+	`)
+
+	sqliteTestOption.OutDir = "testoutdir/sqlite"
+	sqliteTestOption.ParsedTableNames = []string{"test_chunked_export"}
+	sqliteTestOption.Chunks = 4
+	sqliteTestOption.ChunkColumn = "k"
+	defer func() {
+		sqliteTestOption.ParsedTableNames = nil
+		sqliteTestOption.Chunks = 0
+		sqliteTestOption.ChunkColumn = ""
+	}()
+	commandOption = sqliteTestOption
+	summary := exec(context.Background())
+
+	if r := summary.Results[0]; r.Rows != 10 || r.Err != nil {
+		t.Errorf("want: (10, nil), but got (%d, %v)", r.Rows, r.Err)
+	}
+	AssertCompareFiles(t, "testoutdir/sqlite/test_chunked_export.csv", "testdata/sqlite/test_chunked_export.csv")
+
+	spools, _ := filepath.Glob("testoutdir/sqlite/.test_chunked_export.chunk-*.tmp")
+	if len(spools) != 0 {
+		t.Errorf("want spool files removed, but got %v", spools)
+	}
+}
+
+func TestSqliteChunkParts(t *testing.T) {
+	// Create table for test
+	execSqliteTestSQL(`
+		DROP TABLE IF EXISTS test_chunk_parts;
+		CREATE TABLE test_chunk_parts (
+			id INTEGER NOT NULL PRIMARY KEY,
+			created_at DATETIME
+		);
+	`)
+	// Insert test data
+	execSqliteTestSQL(`
+		WITH RECURSIVE seq(n) AS (SELECT 1 UNION ALL SELECT n + 1 FROM seq WHERE n < 1000)
+		INSERT INTO test_chunk_parts SELECT n, datetime('2025-03-22', '+' || n || ' minutes') FROM seq;
+	`)
+
+	for _, column := range []string{"", "created_at"} {
+		outdir := filepath.Join("testoutdir/sqlite/chunk_parts", column)
+		// parts of a previous export split into more chunks.
+		if err := os.MkdirAll(outdir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		for _, stale := range []string{"test_chunk_parts.part-0004.csv", "test_chunk_parts.part-0005.csv"} {
+			if err := os.WriteFile(filepath.Join(outdir, stale), []byte("id,created_at\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		sqliteTestOption.OutDir = outdir
+		sqliteTestOption.ParsedTableNames = []string{"test_chunk_parts"}
+		sqliteTestOption.Chunks = 3
+		sqliteTestOption.ChunkColumn = column
+		sqliteTestOption.ChunkParts = true
+		commandOption = sqliteTestOption
+		summary := exec(context.Background())
+		sqliteTestOption.ParsedTableNames = nil
+		sqliteTestOption.Chunks = 0
+		sqliteTestOption.ChunkColumn = ""
+		sqliteTestOption.ChunkParts = false
+
+		if r := summary.Results[0]; r.Rows != 1000 || r.Err != nil {
+			t.Errorf("column '%s': want: (1000, nil), but got (%d, %v)", column, r.Rows, r.Err)
+		}
+
+		parts, _ := filepath.Glob(filepath.Join(outdir, "test_chunk_parts.part-*.csv"))
+		if len(parts) != 3 {
+			t.Fatalf("column '%s': want parts: 3, but got %v", column, parts)
+		}
+
+		// every row is in exactly one of the parts.
+		seen := make(map[string]int)
+		for _, part := range parts {
+			f, err := os.Open(part)
+			if err != nil {
+				t.Fatal(err)
+			}
+			records, err := csv.NewReader(f).ReadAll()
+			f.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, record := range records[1:] {
+				seen[record[0]]++
+			}
+		}
+		if len(seen) != 1000 {
+			t.Errorf("column '%s': want ids: 1000, but got %d", column, len(seen))
+		}
+		for id, n := range seen {
+			if n != 1 {
+				t.Errorf("column '%s': want id %s once, but got %d times", column, id, n)
+			}
+		}
+	}
+}
+
+func TestSqliteChunkPartsFailedChunk(t *testing.T) {
+	// Create table for test
+	execSqliteTestSQL(`
+		DROP TABLE IF EXISTS test_chunk_parts_source;
+		DROP VIEW IF EXISTS test_chunk_parts_failed;
+		CREATE TABLE test_chunk_parts_source (id INTEGER NOT NULL PRIMARY KEY);
+		CREATE VIEW test_chunk_parts_failed AS SELECT id, id AS val_col FROM test_chunk_parts_source;
+	`)
+	// Insert test data
+	execSqliteTestSQL(`
+		WITH RECURSIVE seq(n) AS (SELECT 1 UNION ALL SELECT n + 1 FROM seq WHERE n < 90)
+		INSERT INTO test_chunk_parts_source SELECT n FROM seq;
+	`)
+
+	outdir := "testoutdir/sqlite/chunk_parts_failed"
+	RemoveTestOutputFile(outdir)
+	sqliteTestOption.OutDir = outdir
+	sqliteTestOption.ParsedTableNames = []string{"test_chunk_parts_failed"}
+	sqliteTestOption.Chunks = 3
+	sqliteTestOption.ChunkColumn = "id"
+	sqliteTestOption.ChunkParts = true
+	defer func() {
+		sqliteTestOption.ParsedTableNames = nil
+		sqliteTestOption.Chunks = 0
+		sqliteTestOption.ChunkColumn = ""
+		sqliteTestOption.ChunkParts = false
+	}()
+	commandOption = sqliteTestOption
+
+	summary := exec(context.Background())
+	if r := summary.Results[0]; r.Rows != 90 || r.Err != nil {
+		t.Fatalf("want: (90, nil), but got (%d, %v)", r.Rows, r.Err)
+	}
+	previous := make(map[string][]byte)
+	parts, _ := filepath.Glob(filepath.Join(outdir, "test_chunk_parts_failed.part-*.csv"))
+	for _, part := range parts {
+		data, err := os.ReadFile(part)
+		if err != nil {
+			t.Fatal(err)
+		}
+		previous[part] = data
+	}
+	if len(previous) != 3 {
+		t.Fatalf("want parts: 3, but got %v", parts)
+	}
+
+	// the last chunk fails, after the others are written.
+	execSqliteTestSQL(`
+		DROP VIEW test_chunk_parts_failed;
+		CREATE VIEW test_chunk_parts_failed AS
+			SELECT id, CASE WHEN id < 61 THEN id * 10 ELSE abs(-9223372036854775807 - 1) END AS val_col FROM test_chunk_parts_source;
+	`)
+	summary = exec(context.Background())
+	if r := summary.Results[0]; r.Err == nil || len(r.Files) != 0 {
+		t.Errorf("want failed without files, but got (%v, %v)", r.Files, r.Err)
+	}
+
+	parts, _ = filepath.Glob(filepath.Join(outdir, "test_chunk_parts_failed.part-*.csv"))
+	if len(parts) != len(previous) {
+		t.Errorf("want the previous parts, but got %v", parts)
+	}
+	for _, part := range parts {
+		data, err := os.ReadFile(part)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != string(previous[part]) {
+			t.Errorf("want %s of the previous export, but got %q", part, data)
+		}
+	}
+	temps, _ := filepath.Glob(filepath.Join(outdir, ".test_chunk_parts_failed.part-*.tmp"))
+	if len(temps) != 0 {
+		t.Errorf("want temporary parts removed, but got %v", temps)
+	}
+}
+
+func TestSqliteConsistentSnapshot(t *testing.T) {
+	// a database in WAL mode of its own, so that rows can be written while
+	// the snapshot is open.
+	database := "testoutdir/consistent_database.sqlite3"
+	os.Remove(database)
+	db, err := sql.Open("sqlite", database)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(`
+		PRAGMA journal_mode = WAL;
+		CREATE TABLE test_consistent (id INTEGER NOT NULL PRIMARY KEY);
+		INSERT INTO test_consistent VALUES (1), (2), (3);
+	`); err != nil {
+		t.Fatal(err)
+	}
+
+	option := *sqliteTestOption
+	option.Database = database
+	commandOption = &option
+	operator := NewSQLiteOperator()
+	if err := operator.DBOpen(context.Background()); err != nil {
+		t.Fatalf("open database failed: %v", err)
+	}
+	defer operator.DBClose()
+
+	countRows := func() int {
+		rows, err := operator.QueryAllRecords(context.Background(), "test_consistent")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rows.Close()
+		n := 0
+		for rows.Next() {
+			n++
+		}
+		return n
+	}
+
+	if err := operator.BeginSnapshot(context.Background()); err != nil {
+		t.Fatalf("begin snapshot failed: %v", err)
+	}
+	if n := countRows(); n != 3 {
+		t.Errorf("want: 3, but got %d", n)
+	}
+	if _, err := db.Exec("INSERT INTO test_consistent VALUES (4)"); err != nil {
+		t.Fatal(err)
+	}
+	if n := countRows(); n != 3 {
+		t.Errorf("want rows in the snapshot: 3, but got %d", n)
+	}
+	if err := operator.EndSnapshot(); err != nil {
+		t.Fatalf("end snapshot failed: %v", err)
+	}
+	if n := countRows(); n != 4 {
+		t.Errorf("want rows after the snapshot: 4, but got %d", n)
+	}
+}
+
+func TestSqliteCanceledExport(t *testing.T) {
+	// Create table for test
+	execSqliteTestSQL(`
+		DROP TABLE IF EXISTS test_canceled_export;
+		CREATE TABLE test_canceled_export (id INTEGER NOT NULL PRIMARY KEY);
+	`)
+	// Insert test data
+	execSqliteTestSQL(`
+		INSERT INTO test_canceled_export VALUES (1), (2), (3);
+	`)
+
+	sqliteTestOption.OutDir = "testoutdir/sqlite/canceled"
+	commandOption = sqliteTestOption
+	operator := NewSQLiteOperator()
+	if err := operator.DBOpen(context.Background()); err != nil {
+		t.Fatalf("open database failed: %v", err)
+	}
+	defer operator.DBClose()
+
+	// canceled while the rows are written.
+	rows, err := operator.QueryAllRecords(context.Background(), "test_canceled_export")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = writeTableOutput(ctx, operator, "test_canceled_export", "test_canceled_export", rows)
+	rows.Close()
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("want error: '%s', but got '%v'", context.Canceled, err)
+	}
+	if _, err := os.Stat("testoutdir/sqlite/canceled/test_canceled_export.csv"); !os.IsNotExist(err) {
+		t.Errorf("want the partial file removed, but got '%v'", err)
+	}
+
+	// canceled before the table was started.
+	var result TableResult
+	for _, job := range tableJobs(ctx, operator, "test_canceled_export", &result) {
+		job()
+	}
+	if result.Err != context.Canceled {
+		t.Errorf("want error: '%s', but got '%v'", context.Canceled, result.Err)
+	}
+	if _, err := os.Stat("testoutdir/sqlite/canceled/test_canceled_export.csv"); !os.IsNotExist(err) {
+		t.Errorf("want no output file, but got '%v'", err)
+	}
+}
+
+func TestSqliteJSONLOutput(t *testing.T) {
+	// Create table for test
+	execSqliteTestSQL(`
+		DROP TABLE IF EXISTS test_jsonl_output;
+		CREATE TABLE test_jsonl_output (
+			id INTEGER NOT NULL PRIMARY KEY,
+			real_col REAL,
+			text_col TEXT,
+			bool_col BOOLEAN,
+			blob_col BLOB,
+			int_col INT
+		);
+	`)
+	// Insert test data
+	execSqliteTestSQL(`
+		INSERT INTO test_jsonl_output VALUES (1, 3.14, 'test row 1', true, x'DEADBEEF', 10);
+		INSERT INTO test_jsonl_output VALUES (2, NULL, 'TEST,"STRING"<>', false, NULL, 'NULL');
+		INSERT INTO test_jsonl_output VALUES (3, -1.5E+100, '', NULL, x'', NULL);
+	`)
+
+	sqliteTestOption.OutDir = "testoutdir/sqlite"
+	sqliteTestOption.Format = OutputFormatJSONL
+	defer func() { sqliteTestOption.Format = OutputFormatCSV }()
+	commandOption = sqliteTestOption
+	exec(context.Background())
+
+	AssertCompareFiles(t, "testoutdir/sqlite/test_jsonl_output.jsonl", "testdata/sqlite/test_jsonl_output.jsonl")
+}
+
+func TestSqliteSQLOutput(t *testing.T) {
+	// Create table for test
+	execSqliteTestSQL(`
+		DROP TABLE IF EXISTS test_sql_output;
+		CREATE TABLE test_sql_output (
+			id INTEGER NOT NULL PRIMARY KEY,
+			real_col REAL,
+			text_col TEXT,
+			datetime_col DATETIME,
+			blob_col BLOB
+		);
+	`)
+	// Insert test data
+	execSqliteTestSQL(`
+		INSERT INTO test_sql_output VALUES (1, 3.14, 'test row 1', '2025-03-22 21:54:24.123', x'DEADBEEF');
+		INSERT INTO test_sql_output VALUES (2, 100, 'it''s "quoted"', NULL, x'');
+		INSERT INTO test_sql_output VALUES (3, NULL, NULL, NULL, NULL);
+	`)
+
+	sqliteTestOption.OutDir = "testoutdir/sqlite"
+	sqliteTestOption.Format = OutputFormatSQL
+	sqliteTestOption.SQLBatchSize = 2
+	defer func() {
+		sqliteTestOption.Format = OutputFormatCSV
+		sqliteTestOption.SQLBatchSize = DefaultSQLBatchSize
+	}()
+	commandOption = sqliteTestOption
+	exec(context.Background())
+
+	AssertCompareFiles(t, "testoutdir/sqlite/test_sql_output.sql", "testdata/sqlite/test_sql_output.sql")
+}
+
+func TestSqliteXLSXOutput(t *testing.T) {
+	// Create table for test
+	execSqliteTestSQL(`
+		DROP TABLE IF EXISTS test_xlsx_output;
+		CREATE TABLE test_xlsx_output (
+			id INTEGER NOT NULL PRIMARY KEY,
+			real_col REAL,
+			text_col TEXT,
+			bool_col BOOLEAN,
+			date_col DATE,
+			datetime_col DATETIME,
+			bigint_col BIGINT
+		);
+	`)
+	// Insert test data
+	execSqliteTestSQL(`
+		INSERT INTO test_xlsx_output VALUES (1, 3.14, '00123', true, '2025-03-22', '2025-03-22 21:54:24.123', 1234567890123456789);
+		INSERT INTO test_xlsx_output VALUES (2, NULL, 'テスト', false, NULL, NULL, 42);
+	`)
+
+	sqliteTestOption.OutDir = "testoutdir/sqlite"
+	sqliteTestOption.Format = OutputFormatXLSX
+	defer func() { sqliteTestOption.Format = OutputFormatCSV }()
+	commandOption = sqliteTestOption
+	exec(context.Background())
+
+	f, err := excelize.OpenFile("testoutdir/sqlite/test_xlsx_output.xlsx")
+	if err != nil {
+		t.Fatalf("open xlsx file failed: %v", err)
+	}
+	defer f.Close()
+
+	if sheets := f.GetSheetList(); !reflect.DeepEqual(sheets, []string{"test_xlsx_output"}) {
+		t.Fatalf("want sheets: [test_xlsx_output], but got %v", sheets)
+	}
+
+	want := []struct {
+		cell string
+		ty   excelize.CellType
+		val  string
+		raw  bool
+	}{
+		{"A1", excelize.CellTypeInlineString, "id", false},
+		{"A2", excelize.CellTypeUnset, "1", false},
+		{"B2", excelize.CellTypeUnset, "3.14", false},
+		{"C2", excelize.CellTypeInlineString, "00123", false},
+		{"D2", excelize.CellTypeBool, "TRUE", false},
+		{"E2", excelize.CellTypeUnset, "2025-03-22", false},
+		// excelize does not render milliseconds, so the serial number is compared
+		{"F2", excelize.CellTypeUnset, "45738.91277920139", true},
+		{"G2", excelize.CellTypeInlineString, "1234567890123456789", false},
+		{"B3", excelize.CellTypeUnset, "", false},
+		{"C3", excelize.CellTypeInlineString, "テスト", false},
+		{"D3", excelize.CellTypeBool, "FALSE", false},
+		{"G3", excelize.CellTypeUnset, "42", false},
+	}
+	for _, w := range want {
+		ty, err := f.GetCellType("test_xlsx_output", w.cell)
+		if err != nil {
+			t.Fatalf("get cell type %s failed: %v", w.cell, err)
+		}
+		val, err := f.GetCellValue("test_xlsx_output", w.cell, excelize.Options{RawCellValue: w.raw})
+		if err != nil {
+			t.Fatalf("get cell value %s failed: %v", w.cell, err)
+		}
+		if ty != w.ty || val != w.val {
+			t.Errorf("cell %s want: (%v, '%s'), but got (%v, '%s')", w.cell, w.ty, w.val, ty, val)
+		}
+	}
+
+	style, err := f.GetCellStyle("test_xlsx_output", "A1")
+	if err != nil {
+		t.Fatalf("get cell style failed: %v", err)
+	}
+	s, err := f.GetStyle(style)
+	if err != nil {
+		t.Fatalf("get style failed: %v", err)
+	}
+	if s.Font == nil || !s.Font.Bold {
+		t.Errorf("want bold header, but got %+v", s.Font)
+	}
+}
+
+func TestSqliteXLSXBookOutput(t *testing.T) {
+	// Create table for test
+	execSqliteTestSQL(`
+		DROP TABLE IF EXISTS test_xlsx_book_1;
+		DROP TABLE IF EXISTS test_xlsx_book_2;
+		CREATE TABLE test_xlsx_book_1 (id INTEGER NOT NULL PRIMARY KEY);
+		CREATE TABLE test_xlsx_book_2 (id INTEGER NOT NULL PRIMARY KEY);
+	`)
+	// Insert test data
+	execSqliteTestSQL(`
+		INSERT INTO test_xlsx_book_1 VALUES (1), (2), (3), (4), (5);
+		INSERT INTO test_xlsx_book_2 VALUES (1);
+	`)
+
+	// 3 rows per sheet: the header and 2 records
+	max_rows := xlsxMaxRows
+	xlsxMaxRows = 3
+	defer func() { xlsxMaxRows = max_rows }()
+
+	sqliteTestOption.OutDir = "testoutdir/sqlite"
+	sqliteTestOption.Format = OutputFormatXLSX
+	sqliteTestOption.XLSXBook = "test_xlsx_book"
+	sqliteTestOption.ParsedTableNames = []string{"test_xlsx_book_1", "test_xlsx_book_2"}
+	defer func() {
+		sqliteTestOption.Format = OutputFormatCSV
+		sqliteTestOption.XLSXBook = ""
+		sqliteTestOption.ParsedTableNames = nil
+	}()
+	commandOption = sqliteTestOption
+	exec(context.Background())
+
+	f, err := excelize.OpenFile("testoutdir/sqlite/test_xlsx_book.xlsx")
+	if err != nil {
+		t.Fatalf("open xlsx file failed: %v", err)
+	}
+	defer f.Close()
+
+	want := map[string][][]string{
+		"test_xlsx_book_1":     {{"id"}, {"1"}, {"2"}},
+		"test_xlsx_book_1 (2)": {{"id"}, {"3"}, {"4"}},
+		"test_xlsx_book_1 (3)": {{"id"}, {"5"}},
+		"test_xlsx_book_2":     {{"id"}, {"1"}},
+	}
+	if len(f.GetSheetList()) != len(want) {
+		t.Fatalf("want sheets: %d, but got %v", len(want), f.GetSheetList())
+	}
+	for sheet, want_rows := range want {
+		rows, err := f.GetRows(sheet)
+		if err != nil {
+			t.Fatalf("get rows of %s failed: %v", sheet, err)
+		}
+		if !reflect.DeepEqual(rows, want_rows) {
+			t.Errorf("sheet %s want: %v, but got %v", sheet, want_rows, rows)
+		}
+	}
+}
+
+func TestSqliteXLSXBookFailedTable(t *testing.T) {
+	// Create table for test
+	execSqliteTestSQL(`
+		DROP TABLE IF EXISTS test_xlsx_book_ok;
+		DROP VIEW IF EXISTS test_xlsx_book_failed;
+		CREATE TABLE test_xlsx_book_ok (id INTEGER NOT NULL PRIMARY KEY);
+		CREATE VIEW test_xlsx_book_failed AS
+			SELECT id, CASE WHEN id < 4 THEN id ELSE abs(-9223372036854775807 - 1) END AS val_col FROM test_xlsx_book_ok;
+	`)
+	// Insert test data, the view fails on its 4th record
+	execSqliteTestSQL(`
+		INSERT INTO test_xlsx_book_ok VALUES (1), (2), (3), (4), (5);
+	`)
+
+	// 3 rows per sheet: the view fails on its second sheet
+	max_rows := xlsxMaxRows
+	xlsxMaxRows = 3
+	defer func() { xlsxMaxRows = max_rows }()
+
+	sqliteTestOption.OutDir = "testoutdir/sqlite"
+	sqliteTestOption.Format = OutputFormatXLSX
+	defer func() {
+		sqliteTestOption.Format = OutputFormatCSV
+		sqliteTestOption.XLSXBook = ""
+		sqliteTestOption.ParsedTableNames = nil
+	}()
+	commandOption = sqliteTestOption
+
+	tests := []struct {
+		book   string
+		tables []string
+		want   map[string][][]string
+	}{
+		{
+			book:   "test_xlsx_book_failed",
+			tables: []string{"test_xlsx_book_failed", "test_xlsx_book_ok"},
+			want: map[string][][]string{
+				"test_xlsx_book_ok":     {{"id"}, {"1"}, {"2"}},
+				"test_xlsx_book_ok (2)": {{"id"}, {"3"}, {"4"}},
+				"test_xlsx_book_ok (3)": {{"id"}, {"5"}},
+			},
+		},
+		{
+			book:   "test_xlsx_book_all_failed",
+			tables: []string{"test_xlsx_book_failed"},
+			want:   map[string][][]string{"Sheet1": {}},
+		},
+	}
+	for _, tt := range tests {
+		sqliteTestOption.XLSXBook = tt.book
+		sqliteTestOption.ParsedTableNames = tt.tables
+		summary := exec(context.Background())
+		for _, r := range summary.Results {
+			if failed := r.Table == "test_xlsx_book_failed"; failed != (r.Err != nil) {
+				t.Errorf("%s: %s want failed: %v, but got '%v'", tt.book, r.Table, failed, r.Err)
+			}
+		}
+
+		f, err := excelize.OpenFile("testoutdir/sqlite/" + tt.book + ".xlsx")
+		if err != nil {
+			t.Fatalf("open xlsx file failed: %v", err)
+		}
+		if len(f.GetSheetList()) != len(tt.want) {
+			t.Errorf("%s: want sheets: %d, but got %v", tt.book, len(tt.want), f.GetSheetList())
+		}
+		for sheet, want_rows := range tt.want {
+			rows, err := f.GetRows(sheet)
+			if err != nil {
+				t.Errorf("%s: get rows of %s failed: %v", tt.book, sheet, err)
+			}
+			if !reflect.DeepEqual(rows, want_rows) {
+				t.Errorf("%s: sheet %s want: %v, but got %v", tt.book, sheet, want_rows, rows)
+			}
+		}
+		f.Close()
+	}
+}
+
+func TestSqliteParquetColumnNames(t *testing.T) {
+	// Create table for test
+	execSqliteTestSQL(`
+		DROP TABLE IF EXISTS test_parquet_column_names;
+		CREATE TABLE test_parquet_column_names ("a=b" INTEGER, "c,d" INTEGER);
+		INSERT INTO test_parquet_column_names VALUES (1, 2);
+	`)
+
+	sqliteTestOption.OutDir = "testoutdir/sqlite"
+	sqliteTestOption.Format = OutputFormatParquet
+	defer func() { sqliteTestOption.Format = OutputFormatCSV }()
+	commandOption = sqliteTestOption
+	operator := NewSQLiteOperator()
+	if err := operator.DBOpen(context.Background()); err != nil {
+		t.Fatalf("open database failed: %v", err)
+	}
+	defer operator.DBClose()
+
+	for query, wantErr := range map[string]bool{
+		`SELECT "a=b" FROM test_parquet_column_names`:         false,
+		`SELECT "c,d" FROM test_parquet_column_names`:         true,
+		`SELECT "a=b" AS " e" FROM test_parquet_column_names`: true,
+	} {
+		rows, err := operator.QueryRecords(context.Background(), query, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = writeTableOutput(context.Background(), operator, "test_parquet_column_names", "test_parquet_column_names", rows)
+		rows.Close()
+		if (err != nil) != wantErr {
+			t.Errorf("%s: want error: %v, but got '%v'", query, wantErr, err)
+		}
+	}
+}
+
+func TestSqliteParquetOutput(t *testing.T) {
+	// Create table for test
+	execSqliteTestSQL(`
+		DROP TABLE IF EXISTS test_parquet_output;
+		CREATE TABLE test_parquet_output (
+			id INTEGER NOT NULL PRIMARY KEY,
+			real_col REAL,
+			text_col TEXT,
+			bool_col BOOLEAN,
+			date_col DATE,
+			datetime_col DATETIME,
+			blob_col BLOB
+		);
+	`)
+	// Insert test data
+	execSqliteTestSQL(`
+		INSERT INTO test_parquet_output VALUES (1, 3.14, 'test row 1', true, '2025-03-22', '2025-03-22 21:54:24.123', x'DEADBEEF');
+		INSERT INTO test_parquet_output VALUES (2, NULL, NULL, NULL, NULL, NULL, NULL);
+		INSERT INTO test_parquet_output VALUES (3, NULL, NULL, 'yes', 'today', 'now', NULL);
+	`)
+
+	sqliteTestOption.OutDir = "testoutdir/sqlite"
+	sqliteTestOption.Format = OutputFormatParquet
+	defer func() { sqliteTestOption.Format = OutputFormatCSV }()
+	commandOption = sqliteTestOption
+	exec(context.Background())
+
+	fr, err := local.NewLocalFileReader("testoutdir/sqlite/test_parquet_output.parquet")
+	if err != nil {
+		t.Fatalf("open parquet file failed: %v", err)
+	}
+	defer fr.Close()
+
+	pr, err := reader.NewParquetColumnReader(fr, 1)
+	if err != nil {
+		t.Fatalf("read parquet file failed: %v", err)
+	}
+	defer pr.ReadStop()
+
+	if pr.GetNumRows() != 3 {
+		t.Fatalf("want rows: 3, but got %d", pr.GetNumRows())
+	}
+
+	// booleans, dates and times are declared types of SQLite which can
+	// hold any text, so they are strings.
+	wantTypes := []parquet.Type{
+		parquet.Type_INT64,
+		parquet.Type_DOUBLE,
+		parquet.Type_BYTE_ARRAY,
+		parquet.Type_BYTE_ARRAY,
+		parquet.Type_BYTE_ARRAY,
+		parquet.Type_BYTE_ARRAY,
+		parquet.Type_BYTE_ARRAY,
+	}
+	wantValues := [][]interface{}{
+		{int64(1), int64(2), int64(3)},
+		{float64(3.14), nil, nil},
+		{"test row 1", nil, nil},
+		{"1", nil, "yes"},
+		{"2025-03-22", nil, "today"},
+		{"2025-03-22 21:54:24.123", nil, "now"},
+		{"\xde\xad\xbe\xef", nil, nil},
+	}
+
+	elements := pr.SchemaHandler.SchemaElements[1:]
+	for i, want := range wantTypes {
+		if elements[i].GetType() != want {
+			t.Errorf("column %d type want: %v, but got %v", i, want, elements[i].GetType())
+		}
+	}
+	if elements[0].GetRepetitionType() != parquet.FieldRepetitionType_OPTIONAL {
+		t.Errorf("column 0 repetition want: OPTIONAL, but got %v", elements[0].GetRepetitionType())
+	}
+	for i := 3; i <= 5; i++ {
+		if elements[i].GetConvertedType() != parquet.ConvertedType_UTF8 {
+			t.Errorf("column %d converted type want: UTF8, but got %v", i, elements[i].GetConvertedType())
+		}
+	}
+
+	for i, want := range wantValues {
+		got, _, _, err := pr.ReadColumnByIndex(int64(i), 3)
+		if err != nil {
+			t.Fatalf("read column %d failed: %v", i, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("column %d want: %v, but got %v", i, want, got)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	TableStatusOK      = "ok"
+	TableStatusFailed  = "failed"
+	TableStatusSkipped = "skipped"
+)
+
+// TableResult is the outcome of exporting one table. Resumed tables were
+// completed by a previous run and skipped with -resume.
+type TableResult struct {
+	Table     string
+	Kind      string
+	Rows      int64
+	Files     []OutputFile
+	Columns   []ColumnInfo
+	StartedAt time.Time
+	Duration  time.Duration
+	Resumed   bool
+	Err       error
+}
+
+func (r TableResult) Status() string {
+	if r.Err != nil {
+		return TableStatusFailed
+	}
+	if r.Resumed {
+		return TableStatusSkipped
+	}
+	return TableStatusOK
+}
+
+func (r TableResult) MarshalJSON() ([]byte, error) {
+	var message string
+	if r.Err != nil {
+		message = r.Err.Error()
+	}
+
+	return json.Marshal(struct {
+		Table      string `json:"table"`
+		Status     string `json:"status"`
+		Rows       int64  `json:"rows"`
+		DurationMs int64  `json:"duration_ms"`
+		Error      string `json:"error,omitempty"`
+	}{r.Table, r.Status(), r.Rows, r.Duration.Milliseconds(), message})
+}
+
+// ExportSummary collects the results of all tables of a run.
+type ExportSummary struct {
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Results    []TableResult
+}
+
+func (s *ExportSummary) Failed() int {
+	failed := 0
+	for _, r := range s.Results {
+		if r.Err != nil {
+			failed++
+		}
+	}
+	return failed
+}
+
+func (s *ExportSummary) Skipped() int {
+	skipped := 0
+	for _, r := range s.Results {
+		if r.Resumed {
+			skipped++
+		}
+	}
+	return skipped
+}
+
+func (s *ExportSummary) TotalRows() int64 {
+	var rows int64
+	for _, r := range s.Results {
+		rows += r.Rows
+	}
+	return rows
+}
+
+// WriteText writes the summary as a table followed by a totals line.
+func (s *ExportSummary) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TABLE\tROWS\tDURATION\tSTATUS")
+	for _, r := range s.Results {
+		status := r.Status()
+		if r.Err != nil {
+			status = fmt.Sprintf("%s: %s", status, r.Err)
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\n", r.Table, r.Rows, r.Duration.Round(time.Millisecond), status)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	skipped := ""
+	if n := s.Skipped(); n > 0 {
+		skipped = fmt.Sprintf(", %d skipped", n)
+	}
+	_, err := fmt.Fprintf(w, "%d tables, %d failed%s, %d rows in %s\n",
+		len(s.Results), s.Failed(), skipped, s.TotalRows(), s.FinishedAt.Sub(s.StartedAt).Round(time.Millisecond))
+	return err
+}
+
+func (s *ExportSummary) MarshalJSON() ([]byte, error) {
+	results := s.Results
+	if results == nil {
+		results = []TableResult{}
+	}
+
+	return json.Marshal(struct {
+		DBPukeVersion string        `json:"db_puke_version"`
+		StartedAt     time.Time     `json:"started_at"`
+		FinishedAt    time.Time     `json:"finished_at"`
+		DurationMs    int64         `json:"duration_ms"`
+		Tables        int           `json:"tables"`
+		Failed        int           `json:"failed"`
+		Skipped       int           `json:"skipped"`
+		Rows          int64         `json:"rows"`
+		Results       []TableResult `json:"results"`
+	}{
+		DBPukeVersion, s.StartedAt, s.FinishedAt, s.FinishedAt.Sub(s.StartedAt).Milliseconds(),
+		len(s.Results), s.Failed(), s.Skipped(), s.TotalRows(), results,
+	})
+}
+
+// WriteJSONFile writes the summary as JSON to path for monitoring tools.
+func (s *ExportSummary) WriteJSONFile(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
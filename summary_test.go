@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func testExportSummary() *ExportSummary {
+	start := time.Date(2025, 3, 22, 21, 54, 24, 0, time.UTC)
+	return &ExportSummary{
+		StartedAt:  start,
+		FinishedAt: start.Add(2500 * time.Millisecond),
+		Results: []TableResult{
+			{Table: "customers", Rows: 1200, Duration: 1234 * time.Millisecond},
+			{Table: "orders", Rows: 10, Duration: 20 * time.Millisecond, Err: fmt.Errorf("connection reset")},
+		},
+	}
+}
+
+func TestExportSummaryText(t *testing.T) {
+	var buf bytes.Buffer
+	if err := testExportSummary().WriteText(&buf); err != nil {
+		t.Fatalf("want error: nil, but got '%s'", err)
+	}
+
+	want := "" +
+		"TABLE      ROWS  DURATION  STATUS\n" +
+		"customers  1200  1.234s    ok\n" +
+		"orders     10    20ms      failed: connection reset\n" +
+		"2 tables, 1 failed, 1210 rows in 2.5s\n"
+	if buf.String() != want {
+		t.Errorf("want:\n%s\nbut got:\n%s", want, buf.String())
+	}
+}
+
+func TestExportSummaryJSONFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "summary.json")
+	if err := testExportSummary().WriteJSONFile(path); err != nil {
+		t.Fatalf("want error: nil, but got '%s'", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read summary file failed: %v", err)
+	}
+
+	var got struct {
+		StartedAt  string `json:"started_at"`
+		DurationMs int64  `json:"duration_ms"`
+		Tables     int    `json:"tables"`
+		Failed     int    `json:"failed"`
+		Rows       int64  `json:"rows"`
+		Results    []struct {
+			Table      string `json:"table"`
+			Status     string `json:"status"`
+			Rows       int64  `json:"rows"`
+			DurationMs int64  `json:"duration_ms"`
+			Error      string `json:"error"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("parse summary file failed: %v", err)
+	}
+
+	if got.StartedAt != "2025-03-22T21:54:24Z" || got.DurationMs != 2500 || got.Tables != 2 || got.Failed != 1 || got.Rows != 1210 {
+		t.Errorf("unexpected totals: %+v", got)
+	}
+	if len(got.Results) != 2 {
+		t.Fatalf("want results: 2, but got %d", len(got.Results))
+	}
+	if r := got.Results[0]; r.Table != "customers" || r.Status != TableStatusOK || r.Rows != 1200 || r.DurationMs != 1234 || r.Error != "" {
+		t.Errorf("unexpected result: %+v", r)
+	}
+	if r := got.Results[1]; r.Table != "orders" || r.Status != TableStatusFailed || r.Error != "connection reset" {
+		t.Errorf("unexpected result: %+v", r)
+	}
+}
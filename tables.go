@@ -0,0 +1,301 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// TablePatternRegexpPrefix marks a table pattern as a regular expression
+// instead of a glob.
+const TablePatternRegexpPrefix = "re:"
+
+const (
+	ObjectKindTable   = "table"
+	ObjectKindView    = "view"
+	ObjectKindSynonym = "synonym"
+	ObjectKindQuery   = "query"
+)
+
+// TableObject is a table of the database, or a view or a synonym which is
+// exported like a table.
+type TableObject struct {
+	Name string
+	Kind string
+}
+
+// tableTypeKind returns the kind of an object by its TABLE_TYPE of
+// INFORMATION_SCHEMA.TABLES.
+func tableTypeKind(tableType string) string {
+	switch tableType {
+	case "VIEW":
+		return ObjectKindView
+	case "SYNONYM":
+		return ObjectKindSynonym
+	default:
+		return ObjectKindTable
+	}
+}
+
+// TablePattern matches table names by a glob such as audit_*, or by a
+// regular expression written as re:<expression>.
+type TablePattern struct {
+	glob   string
+	regexp *regexp.Regexp
+}
+
+func parseTablePattern(s string) (TablePattern, error) {
+	if expr, ok := strings.CutPrefix(s, TablePatternRegexpPrefix); ok {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return TablePattern{}, err
+		}
+		return TablePattern{regexp: re}, nil
+	}
+
+	// path.Match only reports a malformed pattern when it is matched.
+	if _, err := path.Match(s, ""); err != nil {
+		return TablePattern{}, err
+	}
+	return TablePattern{glob: s}, nil
+}
+
+func (p TablePattern) Match(table string) bool {
+	if p.regexp != nil {
+		return p.regexp.MatchString(table)
+	}
+	matched, _ := path.Match(p.glob, table)
+	return matched
+}
+
+func parseTablePatterns(patterns []string) ([]TablePattern, error) {
+	var parsed []TablePattern
+	for _, s := range patterns {
+		p, err := parseTablePattern(s)
+		if err != nil {
+			return nil, fmt.Errorf("error: invalid table pattern '%s' (-include, -exclude). %s\n", s, err)
+		}
+		parsed = append(parsed, p)
+	}
+	return parsed, nil
+}
+
+// filterTables returns the tables matching any of the -include patterns,
+// or all tables without them, that match none of the -exclude patterns.
+func filterTables(tables []string) []string {
+	matchAny := func(patterns []TablePattern, table string) bool {
+		for _, p := range patterns {
+			if p.Match(table) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var filtered []string
+	for _, table := range tables {
+		if len(commandOption.ParsedIncludes) > 0 && !matchAny(commandOption.ParsedIncludes, table) {
+			continue
+		}
+		if matchAny(commandOption.ParsedExcludes, table) {
+			continue
+		}
+		filtered = append(filtered, table)
+	}
+	return filtered
+}
+
+// readTableFile reads the table names of -t @<file>, one per line. Blank
+// lines and lines starting with # are ignored.
+func readTableFile(name string) ([]string, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	tables := make([]string, 0)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		tables = append(tables, line)
+	}
+	return tables, scanner.Err()
+}
+
+// exportTables returns the tables to export: the queries in query mode,
+// the tables named by -t, or
+// all tables of the database (with the views and synonyms by -views),
+// filtered by -include and -exclude. The kinds of the tables are returned
+// in kinds, and named tables which are not in the database in missing.
+func exportTables(ctx context.Context, operator DBPukeOperator) (tables []string, kinds map[string]string, missing map[string]bool, err error) {
+	if queryMode() {
+		tables, kinds = queryNames()
+		return tables, kinds, nil, nil
+	}
+
+	all, err := operator.GetTableNames(ctx)
+	kinds = make(map[string]string)
+	if len(commandOption.ParsedTableNames) == 0 {
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		var names []string
+		for _, object := range all {
+			if object.Kind == ObjectKindTable || commandOption.Views {
+				names = append(names, object.Name)
+				kinds[object.Name] = object.Kind
+			}
+		}
+		return filterTables(names), kinds, nil, nil
+	}
+
+	tables = filterTables(commandOption.ParsedTableNames)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to check that the tables exist. '%s'\n", err)
+		return tables, kinds, nil, nil
+	}
+
+	// named views and synonyms are exported without -views. The tables
+	// take the names of the matched objects, as they are quoted in queries.
+	missing = make(map[string]bool)
+	for i, table := range tables {
+		object, ok := findTable(all, table)
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Warning: table '%s' does not exist.\n", table)
+			missing[table] = true
+			continue
+		}
+		tables[i] = object.Name
+		kinds[object.Name] = object.Kind
+	}
+	return tables, kinds, missing, nil
+}
+
+// findTable returns the object named table. Names differing only in case
+// are accepted, as most databases do, when no object has the exact name.
+func findTable(objects []TableObject, table string) (TableObject, bool) {
+	for _, object := range objects {
+		if object.Name == table {
+			return object, true
+		}
+	}
+	for _, object := range objects {
+		if strings.EqualFold(object.Name, table) {
+			return object, true
+		}
+	}
+	return TableObject{}, false
+}
+
+// containsTable reports whether table is in tables, accepting names
+// differing only in case like findTable.
+func containsTable(tables []string, table string) bool {
+	for _, t := range tables {
+		if strings.EqualFold(t, table) {
+			return true
+		}
+	}
+	return false
+}
+
+// SchemaAll is the -s value exporting the tables of all schemas except
+// the system ones.
+const SchemaAll = "*"
+
+const (
+	SchemaLayoutDirectory = "dir"
+	SchemaLayoutPrefix    = "prefix"
+)
+
+// parseSchemaOption splits the comma-separated schemas of -s.
+func parseSchemaOption(opstr string) []string {
+	var schemas []string
+	for _, schema := range strings.Split(opstr, ",") {
+		schema = strings.Trim(schema, " ")
+		if schema != "" {
+			schemas = append(schemas, schema)
+		}
+	}
+	return schemas
+}
+
+// multipleSchemas reports whether -s names more than one schema, or all of
+// them. The tables are then named <schema>.<table>.
+func multipleSchemas() bool {
+	return len(commandOption.Schemas) > 1 || allSchemas()
+}
+
+// allSchemas reports whether -s is *.
+func allSchemas() bool {
+	for _, schema := range commandOption.Schemas {
+		if schema == SchemaAll {
+			return true
+		}
+	}
+	return false
+}
+
+// exportTableName returns the name of a table of schema in the export.
+func exportTableName(schema, table string) string {
+	if multipleSchemas() {
+		return schema + "." + table
+	}
+	return table
+}
+
+// splitTableName returns the schema and the name of a table of the
+// export.
+func splitTableName(table string) (schema, name string) {
+	if multipleSchemas() {
+		if schema, name, ok := strings.Cut(table, "."); ok {
+			return schema, name
+		}
+	}
+	return commandOption.Schema, table
+}
+
+// schemaCondition returns the condition on column selecting the schemas of
+// -s, with the arguments of its placeholders. With -s * it selects all but
+// the system schemas.
+func schemaCondition(column string, systemSchemas []string, placeholder func(n int) string) (string, []any) {
+	if allSchemas() {
+		literals := make([]string, len(systemSchemas))
+		for i, s := range systemSchemas {
+			literals[i] = "'" + s + "'"
+		}
+		return fmt.Sprintf("%s NOT IN (%s)", column, strings.Join(literals, ", ")), nil
+	}
+
+	schemas := commandOption.Schemas
+	if len(schemas) == 0 {
+		// options not parsed from -s, such as those of the tests.
+		schemas = []string{commandOption.Schema}
+	}
+	placeholders := make([]string, len(schemas))
+	args := make([]any, len(schemas))
+	for i, s := range schemas {
+		placeholders[i] = placeholder(i + 1)
+		args[i] = s
+	}
+	return fmt.Sprintf("%s IN (%s)", column, strings.Join(placeholders, ", ")), args
+}
+
+// tableFileName returns the output file name of a table, or of a part of
+// it, without the extension. With multiple schemas the files are put in a
+// directory per schema, or named <schema>.<table> by -schema-layout prefix.
+func tableFileName(table string) string {
+	if !multipleSchemas() || commandOption.SchemaLayout == SchemaLayoutPrefix {
+		return table
+	}
+	schema, name := splitTableName(table)
+	return filepath.Join(schema, name)
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestTablePattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		table   string
+		want    bool
+	}{
+		{"audit_*", "audit_log", true},
+		{"audit_*", "orders_audit", false},
+		{"*_bak", "orders_bak", true},
+		{"order?", "orders", true},
+		{"order?", "order", false},
+		{`re:^audit_\d+$`, "audit_2024", true},
+		{`re:^audit_\d+$`, "audit_log", false},
+		{"re:bak", "orders_bak_old", true},
+	}
+
+	for _, tt := range tests {
+		p, err := parseTablePattern(tt.pattern)
+		if err != nil {
+			t.Fatalf("want error: 'nil', but got '%s'", err)
+		}
+		if got := p.Match(tt.table); got != tt.want {
+			t.Errorf("%s matches %s: want %v, but got %v", tt.pattern, tt.table, tt.want, got)
+		}
+	}
+
+	for _, pattern := range []string{"[a-", "re:(a"} {
+		if _, err := parseTablePattern(pattern); err == nil {
+			t.Errorf("want error for %s, but got nil", pattern)
+		}
+	}
+}
+
+func TestFilterTables(t *testing.T) {
+	option := *sqliteTestOption
+	commandOption = &option
+	tables := []string{"orders", "orders_bak", "audit_log", "customers"}
+
+	tests := []struct {
+		name     string
+		includes []string
+		excludes []string
+		want     []string
+	}{
+		{"none", nil, nil, tables},
+		{"exclude", nil, []string{"audit_*", "*_bak"}, []string{"orders", "customers"}},
+		{"include", []string{"orders*"}, nil, []string{"orders", "orders_bak"}},
+		{"include and exclude", []string{"orders*", "re:^cust"}, []string{"*_bak"}, []string{"orders", "customers"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			option.ParsedIncludes, _ = parseTablePatterns(tt.includes)
+			option.ParsedExcludes, _ = parseTablePatterns(tt.excludes)
+			if got := filterTables(tables); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("want: %v, but got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFindTable(t *testing.T) {
+	objects := []TableObject{
+		{Name: "Orders", Kind: ObjectKindTable},
+		{Name: "orders", Kind: ObjectKindView},
+		{Name: "Customers", Kind: ObjectKindTable},
+	}
+
+	tests := []struct {
+		table string
+		want  string
+		ok    bool
+	}{
+		{"Orders", "Orders", true},
+		{"orders", "orders", true},
+		{"customers", "Customers", true},
+		{"items", "", false},
+	}
+
+	for _, tt := range tests {
+		object, ok := findTable(objects, tt.table)
+		if ok != tt.ok || object.Name != tt.want {
+			t.Errorf("%s: want (%s, %v), but got (%s, %v)", tt.table, tt.want, tt.ok, object.Name, ok)
+		}
+	}
+}
+
+func TestTableFileOption(t *testing.T) {
+	if err := os.MkdirAll("testoutdir", 0755); err != nil {
+		t.Fatal(err)
+	}
+	list := "testoutdir/tables.txt"
+	if err := os.WriteFile(list, []byte("# tables to export\norders\n\n  customers  \n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	option, err := parseArgs([]string{
+		"db-puke",
+		"sqlite",
+		"-d",
+		"testdata/sqlite/test_integer_column_table.csv",
+		"-t",
+		"@" + list,
+		"-exclude",
+		"*_bak",
+		"-exclude",
+		"re:^audit_",
+	}, io.Discard)
+	if err != nil {
+		t.Fatalf("want error: 'nil', but got '%s'", err)
+	}
+	if want := []string{"orders", "customers"}; !reflect.DeepEqual(option.ParsedTableNames, want) {
+		t.Errorf("want: %v, but got %v", want, option.ParsedTableNames)
+	}
+	if len(option.ParsedExcludes) != 2 {
+		t.Errorf("want 2 exclude patterns, but got %d", len(option.ParsedExcludes))
+	}
+
+	_, err = parseArgs([]string{"db-puke", "sqlite", "-d", "testdata/sqlite/test_integer_column_table.csv", "-t", "@testoutdir/missing.txt"}, io.Discard)
+	if err == nil {
+		t.Errorf("want error for a missing table list, but got nil")
+	}
+
+	_, err = parseArgs([]string{"db-puke", "sqlite", "-d", "testdata/sqlite/test_integer_column_table.csv", "-include", "re:(a"}, io.Discard)
+	if err == nil {
+		t.Errorf("want error for an invalid pattern, but got nil")
+	}
+}
+
+func TestSchemaTableNames(t *testing.T) {
+	option := *postgresTestOption
+	commandOption = &option
+
+	option.Schemas = parseSchemaOption("dummy_schema")
+	if got := exportTableName("dummy_schema", "orders"); got != "orders" {
+		t.Errorf("want: orders, but got %s", got)
+	}
+	if schema, name := splitTableName("orders"); schema != "dummy_schema" || name != "orders" {
+		t.Errorf("want: dummy_schema orders, but got %s %s", schema, name)
+	}
+	if got := tableFileName("orders"); got != "orders" {
+		t.Errorf("want: orders, but got %s", got)
+	}
+
+	option.Schemas = parseSchemaOption(" sales, hr ")
+	if want := []string{"sales", "hr"}; !reflect.DeepEqual(option.Schemas, want) {
+		t.Errorf("want: %v, but got %v", want, option.Schemas)
+	}
+	if got := exportTableName("sales", "orders"); got != "sales.orders" {
+		t.Errorf("want: sales.orders, but got %s", got)
+	}
+	if schema, name := splitTableName("sales.orders"); schema != "sales" || name != "orders" {
+		t.Errorf("want: sales orders, but got %s %s", schema, name)
+	}
+
+	option.SchemaLayout = SchemaLayoutDirectory
+	if got, want := tableFileName("sales.orders.part-0001"), filepath.Join("sales", "orders.part-0001"); got != want {
+		t.Errorf("want: %s, but got %s", want, got)
+	}
+	option.SchemaLayout = SchemaLayoutPrefix
+	if got := tableFileName("sales.orders"); got != "sales.orders" {
+		t.Errorf("want: sales.orders, but got %s", got)
+	}
+}
+
+func TestSchemaCondition(t *testing.T) {
+	option := *postgresTestOption
+	commandOption = &option
+	placeholder := func(n int) string { return fmt.Sprintf("$%d", n) }
+
+	option.Schemas = []string{"sales", "hr"}
+	cond, args := schemaCondition("table_schema", []string{"pg_catalog"}, placeholder)
+	if want := "table_schema IN ($1, $2)"; cond != want {
+		t.Errorf("want: %s, but got %s", want, cond)
+	}
+	if want := []any{"sales", "hr"}; !reflect.DeepEqual(args, want) {
+		t.Errorf("want: %v, but got %v", want, args)
+	}
+
+	option.Schemas = []string{SchemaAll}
+	cond, args = schemaCondition("table_schema", []string{"pg_catalog", "information_schema"}, placeholder)
+	if want := "table_schema NOT IN ('pg_catalog', 'information_schema')"; cond != want {
+		t.Errorf("want: %s, but got %s", want, cond)
+	}
+	if len(args) != 0 {
+		t.Errorf("want no arguments, but got %v", args)
+	}
+
+	option.Schemas = nil
+	cond, args = schemaCondition("table_schema", []string{"pg_catalog"}, placeholder)
+	if want := "table_schema IN ($1)"; cond != want {
+		t.Errorf("want: %s, but got %s", want, cond)
+	}
+	if want := []any{"dummy_schema"}; !reflect.DeepEqual(args, want) {
+		t.Errorf("want: %v, but got %v", want, args)
+	}
+}
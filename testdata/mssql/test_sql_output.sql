@@ -0,0 +1,7 @@
+SET IDENTITY_INSERT [dummy_schema].[test_sql_output] ON;
+INSERT INTO [dummy_schema].[test_sql_output] ([col1], [col2], [col3], [col4], [col5], [col6], [col7]) VALUES
+(1, N'test row 1', 3.14, 1, CONVERT(DATE, '2025-03-22', 23), CONVERT(DATETIME2, '2025-03-22 21:54:24.1234567', 121), 0xDEADBEEF),
+(2, N'it''s テスト', NULL, 0, NULL, NULL, NULL);
+INSERT INTO [dummy_schema].[test_sql_output] ([col1], [col2], [col3], [col4], [col5], [col6], [col7]) VALUES
+(3, NULL, NULL, NULL, NULL, NULL, NULL);
+SET IDENTITY_INSERT [dummy_schema].[test_sql_output] OFF;
@@ -0,0 +1,5 @@
+INSERT INTO "test_sql_output" ("id", "real_col", "text_col", "datetime_col", "blob_col") VALUES
+(1, 3.14, 'test row 1', '2025-03-22 21:54:24.123', X'DEADBEEF'),
+(2, 100.0, 'it''s "quoted"', NULL, X'');
+INSERT INTO "test_sql_output" ("id", "real_col", "text_col", "datetime_col", "blob_col") VALUES
+(3, NULL, NULL, NULL, NULL);
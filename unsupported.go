@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	UnsupportedPolicyPlaceholder = "placeholder"
+	UnsupportedPolicyError       = "error"
+	UnsupportedPolicySkipColumn  = "skip-column"
+	UnsupportedPolicyCast        = "cast"
+)
+
+// UnsupportedColumn is a column whose type the operator cannot format.
+type UnsupportedColumn struct {
+	Table        string
+	Column       string
+	DatabaseType string
+}
+
+// tableColumns are the columns of a table found by the pre-flight pass.
+type tableColumns struct {
+	names       []string
+	unsupported map[string]string // column name to database type
+}
+
+// exportColumns holds the columns of the tables of the running export, so
+// that the operators can skip or cast the unsupported ones by -unsupported.
+var exportColumns map[string]*tableColumns
+
+// checkColumns is the pre-flight pass reading the columns of all tables
+// before anything is exported. Tables whose columns cannot be read are
+// left out, and their export reports the error.
+func checkColumns(ctx context.Context, operator DBPukeOperator, tables []string) (map[string]*tableColumns, []UnsupportedColumn) {
+	columns := make(map[string]*tableColumns)
+	var unsupported []UnsupportedColumn
+	if queryMode() {
+		// the columns of queries are not known beforehand, so only the
+		// placeholder policy is allowed with them.
+		return columns, nil
+	}
+	for _, table := range tables {
+		types, err := operator.ColumnTypes(ctx, table)
+		if err != nil {
+			continue
+		}
+
+		t := &tableColumns{unsupported: make(map[string]string)}
+		for _, ty := range types {
+			t.names = append(t.names, ty.Name())
+			if operator.ColumnKind(ty) == ColumnKindUnsupported {
+				t.unsupported[ty.Name()] = ty.DatabaseTypeName()
+				unsupported = append(unsupported, UnsupportedColumn{Table: table, Column: ty.Name(), DatabaseType: ty.DatabaseTypeName()})
+			}
+		}
+		columns[table] = t
+	}
+	return columns, unsupported
+}
+
+// printUnsupportedColumns lists the unsupported columns with what the
+// export does with them.
+func printUnsupportedColumns(w io.Writer, columns []UnsupportedColumn) {
+	if len(columns) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "Columns of unsupported types (-unsupported %s):\n", commandOption.UnsupportedPolicy)
+	for _, c := range columns {
+		fmt.Fprintf(w, "  %s.%s %s\n", c.Table, c.Column, c.DatabaseType)
+	}
+}
+
+// unsupportedColumnsError returns the error failing table before it is
+// exported: with -unsupported error when it has unsupported columns, and
+// with skip-column when no column would be left.
+func unsupportedColumnsError(table string) error {
+	t := exportColumns[table]
+	if t == nil || len(t.unsupported) == 0 {
+		return nil
+	}
+
+	switch commandOption.UnsupportedPolicy {
+	case UnsupportedPolicyError:
+		var columns []string
+		for _, name := range t.names {
+			if ty, ok := t.unsupported[name]; ok {
+				columns = append(columns, fmt.Sprintf("%s (%s)", name, ty))
+			}
+		}
+		return fmt.Errorf("unsupported column types: %s", strings.Join(columns, ", "))
+	case UnsupportedPolicySkipColumn:
+		if len(t.unsupported) == len(t.names) {
+			return fmt.Errorf("all columns have unsupported types")
+		}
+	}
+	return nil
+}
+
+// selectList returns the columns to select from table: "*", or the
+// columns without the unsupported ones skipped or cast by -unsupported.
+func selectList(operator DBPukeOperator, table string) string {
+	t := exportColumns[table]
+	if t == nil || len(t.unsupported) == 0 {
+		return "*"
+	}
+	switch commandOption.UnsupportedPolicy {
+	case UnsupportedPolicySkipColumn, UnsupportedPolicyCast:
+	default:
+		return "*"
+	}
+
+	var columns []string
+	for _, name := range t.names {
+		if column, ok := columnExpression(operator, table, name, operator.QuoteIdentifier(name)); ok {
+			columns = append(columns, column)
+		}
+	}
+	return strings.Join(columns, ", ")
+}
+
+// columnExpression applies -unsupported to the select expression of a
+// column. It returns false when the column is skipped.
+func columnExpression(operator DBPukeOperator, table, name, expr string) (string, bool) {
+	if t := exportColumns[table]; t != nil {
+		if _, ok := t.unsupported[name]; ok {
+			switch commandOption.UnsupportedPolicy {
+			case UnsupportedPolicySkipColumn:
+				return "", false
+			case UnsupportedPolicyCast:
+				return fmt.Sprintf("%s AS %s", operator.CastToText(expr), operator.QuoteIdentifier(name)), true
+			}
+		}
+	}
+	return expr, true
+}
+
+// queryColumnTypes returns the column types of the result of a query
+// selecting no rows.
+func queryColumnTypes(ctx context.Context, db *sql.DB, query string) ([]*sql.ColumnType, error) {
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return rows.ColumnTypes()
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestUnsupportedColumnPolicy(t *testing.T) {
+	option := *sqliteTestOption
+	commandOption = &option
+	exportColumns = map[string]*tableColumns{
+		"t": {
+			names:       []string{"id", "location", "name"},
+			unsupported: map[string]string{"location": "POINT"},
+		},
+		"all": {
+			names:       []string{"location"},
+			unsupported: map[string]string{"location": "POINT"},
+		},
+	}
+	defer func() { exportColumns = nil }()
+	operator := NewSQLiteOperator()
+
+	tests := []struct {
+		policy     string
+		wantSelect string
+		wantErr    string
+		wantAllErr string
+	}{
+		{UnsupportedPolicyPlaceholder, `*`, "", ""},
+		{UnsupportedPolicyError, `*`, "unsupported column types: location (POINT)", "unsupported column types: location (POINT)"},
+		{UnsupportedPolicySkipColumn, `"id", "name"`, "", "all columns have unsupported types"},
+		{UnsupportedPolicyCast, `"id", CAST("location" AS TEXT) AS "location", "name"`, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			option.UnsupportedPolicy = tt.policy
+
+			if got := selectList(operator, "t"); got != tt.wantSelect {
+				t.Errorf("want: %s, but got %s", tt.wantSelect, got)
+			}
+			if got := selectList(operator, "other"); got != "*" {
+				t.Errorf("want: * for a table without unsupported columns, but got %s", got)
+			}
+
+			for table, want := range map[string]string{"t": tt.wantErr, "all": tt.wantAllErr} {
+				err := unsupportedColumnsError(table)
+				if (err == nil && want != "") || (err != nil && err.Error() != want) {
+					t.Errorf("want error of %s: '%s', but got '%v'", table, want, err)
+				}
+			}
+		})
+	}
+}
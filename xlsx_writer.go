@@ -0,0 +1,362 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	XLSXMaxSheetNameLength   = 31
+	XLSXMaxSignificantDigits = 15
+)
+
+var (
+	// xlsxMaxRows is the number of rows, header included, written to a
+	// sheet before continuing on a new one. Excel cannot open more.
+	xlsxMaxRows = 1048576
+
+	// sharedWorkbook collects every table as a sheet when -xlsx-book is set.
+	sharedWorkbook *XLSXWorkbook
+)
+
+// XLSXWorkbook is an Excel workbook that sheets can be added to from
+// several table exports at once.
+type XLSXWorkbook struct {
+	mu               sync.Mutex
+	file             *excelize.File
+	defaultSheetUsed bool
+	sheets           map[string][]*xlsxSheet // sheets of each table
+	headerStyle      int
+	dateStyle        int
+	dateTimeStyle    int
+	dateTimeMsStyle  int
+}
+
+func NewXLSXWorkbook() (*XLSXWorkbook, error) {
+	f := excelize.NewFile()
+	book := &XLSXWorkbook{file: f, sheets: make(map[string][]*xlsxSheet)}
+
+	var err error
+	if book.headerStyle, err = f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}}); err != nil {
+		return nil, err
+	}
+	date_format := "yyyy-mm-dd"
+	if book.dateStyle, err = f.NewStyle(&excelize.Style{CustomNumFmt: &date_format}); err != nil {
+		return nil, err
+	}
+	datetime_format := "yyyy-mm-dd hh:mm:ss"
+	if book.dateTimeStyle, err = f.NewStyle(&excelize.Style{CustomNumFmt: &datetime_format}); err != nil {
+		return nil, err
+	}
+	datetime_ms_format := "yyyy-mm-dd hh:mm:ss.000"
+	if book.dateTimeMsStyle, err = f.NewStyle(&excelize.Style{CustomNumFmt: &datetime_ms_format}); err != nil {
+		return nil, err
+	}
+
+	return book, nil
+}
+
+// xlsxSheet is a sheet of a workbook being written by a stream writer.
+type xlsxSheet struct {
+	name    string
+	stream  *excelize.StreamWriter
+	flushed bool
+}
+
+// newSheet adds a sheet of table named after it, made unique within the
+// workbook, and returns it with a stream writer.
+func (b *XLSXWorkbook) newSheet(table string) (*xlsxSheet, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	name := b.uniqueSheetName(table)
+	if !b.defaultSheetUsed {
+		// a new workbook always has one sheet, so the first table takes it over.
+		b.defaultSheetUsed = true
+		if err := b.file.SetSheetName(b.file.GetSheetName(0), name); err != nil {
+			return nil, err
+		}
+	} else if _, err := b.file.NewSheet(name); err != nil {
+		return nil, err
+	}
+
+	stream, err := b.file.NewStreamWriter(name)
+	if err != nil {
+		return nil, err
+	}
+	sheet := &xlsxSheet{name: name, stream: stream}
+	b.sheets[table] = append(b.sheets[table], sheet)
+	return sheet, nil
+}
+
+// deleteSheets removes the sheets of a table which failed. Their streams
+// are finished first, as the workbook cannot be written with one open.
+func (b *XLSXWorkbook) deleteSheets(table string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, sheet := range b.sheets[table] {
+		if !sheet.flushed {
+			if err := sheet.stream.Flush(); err != nil {
+				return err
+			}
+			sheet.flushed = true
+		}
+		if b.file.SheetCount == 1 {
+			// the last sheet of a workbook cannot be deleted, so an empty one
+			// is left to the next table like the sheet of a new workbook.
+			if _, err := b.file.NewSheet(b.uniqueSheetName("Sheet1")); err != nil {
+				return err
+			}
+			b.defaultSheetUsed = false
+		}
+		if err := b.file.DeleteSheet(sheet.name); err != nil {
+			return err
+		}
+	}
+	delete(b.sheets, table)
+	return nil
+}
+
+func (b *XLSXWorkbook) uniqueSheetName(name string) string {
+	base := sanitizeSheetName(name)
+	candidate := truncateRunes(base, XLSXMaxSheetNameLength)
+	for n := 2; b.hasSheet(candidate); n++ {
+		suffix := fmt.Sprintf(" (%d)", n)
+		candidate = truncateRunes(base, XLSXMaxSheetNameLength-len(suffix)) + suffix
+	}
+	return candidate
+}
+
+func (b *XLSXWorkbook) hasSheet(name string) bool {
+	if !b.defaultSheetUsed {
+		return false
+	}
+	for _, s := range b.file.GetSheetList() {
+		if strings.EqualFold(s, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *XLSXWorkbook) setRow(sheet *xlsxSheet, row int, cells []interface{}, opts ...excelize.RowOpts) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	cell, err := excelize.CoordinatesToCellName(1, row)
+	if err != nil {
+		return err
+	}
+	return sheet.stream.SetRow(cell, cells, opts...)
+}
+
+func (b *XLSXWorkbook) flushSheet(sheet *xlsxSheet) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := sheet.stream.Flush(); err != nil {
+		return err
+	}
+	sheet.flushed = true
+	return nil
+}
+
+func (b *XLSXWorkbook) Write(w io.Writer) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := b.file.Write(w); err != nil {
+		return err
+	}
+	return b.file.Close()
+}
+
+// saveSharedWorkbook writes the shared workbook to <outdir>/<-xlsx-book>.xlsx.
+func saveSharedWorkbook() (OutputFile, error) {
+	fileName, err := getOutputFilePath(commandOption.OutDir, commandOption.XLSXBook)
+	if err != nil {
+		return OutputFile{}, err
+	}
+	file, err := createAtomicFile(fileName)
+	if err != nil {
+		return OutputFile{}, err
+	}
+
+	digest := newOutputDigest(file)
+	if err := sharedWorkbook.Write(digest); err != nil {
+		file.Abort()
+		return OutputFile{}, err
+	}
+	if err := file.Commit(); err != nil {
+		return OutputFile{}, err
+	}
+	return digest.file(file.path, 0), nil
+}
+
+// XLSXWriter writes a table to sheets of a workbook, continuing on a new
+// sheet whenever the current one is full.
+type XLSXWriter struct {
+	book   *XLSXWorkbook
+	kinds  []ColumnKind
+	table  string
+	header []interface{}
+	sheet  *xlsxSheet
+	row    int
+	out    io.Writer
+}
+
+// NewXLSXWriter returns a writer adding sheets to the shared workbook, or
+// to a workbook of its own that is written to w on Flush when there is none.
+func NewXLSXWriter(operator DBPukeOperator, table string, columnTypes []*sql.ColumnType, w io.Writer) (*XLSXWriter, error) {
+	kinds := make([]ColumnKind, len(columnTypes))
+	for i, ty := range columnTypes {
+		kinds[i] = operator.ColumnKind(ty)
+	}
+
+	book := sharedWorkbook
+	if book == nil {
+		var err error
+		book, err = NewXLSXWorkbook()
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		w = nil
+	}
+
+	return &XLSXWriter{
+		book:  book,
+		kinds: kinds,
+		table: table,
+		out:   w,
+	}, nil
+}
+
+func (w *XLSXWriter) WriteHeader(columns []string) error {
+	w.header = make([]interface{}, len(columns))
+	for i, c := range columns {
+		w.header[i] = excelize.Cell{StyleID: w.book.headerStyle, Value: c}
+	}
+	return w.nextSheet()
+}
+
+func (w *XLSXWriter) WriteRecord(record []string, values []any) error {
+	if w.row >= xlsxMaxRows {
+		if err := w.nextSheet(); err != nil {
+			return err
+		}
+	}
+
+	cells := make([]interface{}, len(record))
+	for i := range record {
+		if values[i] == nil {
+			continue
+		}
+		cells[i] = w.cellValue(record[i], w.kinds[i])
+	}
+
+	w.row++
+	return w.book.setRow(w.sheet, w.row, cells)
+}
+
+func (w *XLSXWriter) Flush() error {
+	if err := w.book.flushSheet(w.sheet); err != nil {
+		return err
+	}
+	if w.out == nil {
+		return nil
+	}
+	return w.book.Write(w.out)
+}
+
+// nextSheet finishes the current sheet, if any, and starts a new one
+// beginning with the header row.
+func (w *XLSXWriter) nextSheet() error {
+	if w.sheet != nil {
+		if err := w.book.flushSheet(w.sheet); err != nil {
+			return err
+		}
+	}
+
+	sheet, err := w.book.newSheet(w.table)
+	if err != nil {
+		return err
+	}
+	w.sheet = sheet
+	w.row = 1
+
+	return w.book.setRow(w.sheet, w.row, w.header, excelize.RowOpts{StyleID: w.book.headerStyle})
+}
+
+// cellValue converts a formatted value to a typed cell. Values Excel would
+// not keep exactly, such as integers beyond 15 digits, stay text.
+func (w *XLSXWriter) cellValue(val_str string, kind ColumnKind) interface{} {
+	switch kind {
+	case ColumnKindInteger:
+		if n, err := strconv.ParseInt(val_str, 10, 64); err == nil && significantDigits(val_str) <= XLSXMaxSignificantDigits {
+			return n
+		}
+	case ColumnKindFloat:
+		if f, err := strconv.ParseFloat(val_str, 64); err == nil && !math.IsInf(f, 0) && !math.IsNaN(f) {
+			return f
+		}
+	case ColumnKindDecimal:
+		if f, err := strconv.ParseFloat(val_str, 64); err == nil && significantDigits(val_str) <= XLSXMaxSignificantDigits {
+			return f
+		}
+	case ColumnKindBool:
+		if b, err := strconv.ParseBool(val_str); err == nil {
+			return b
+		}
+	case ColumnKindDate:
+		if t, err := time.Parse("2006-01-02", val_str); err == nil && t.Year() >= 1900 {
+			return excelize.Cell{StyleID: w.book.dateStyle, Value: t}
+		}
+	case ColumnKindDateTime:
+		// values with a time zone offset stay text, as Excel has no zones.
+		if t, err := time.Parse("2006-01-02 15:04:05.999999999", val_str); err == nil && t.Year() >= 1900 {
+			style := w.book.dateTimeStyle
+			if t.Nanosecond() != 0 {
+				style = w.book.dateTimeMsStyle
+			}
+			return excelize.Cell{StyleID: style, Value: t}
+		}
+	}
+	return val_str
+}
+
+// significantDigits counts the digits of a decimal number without its
+// leading zeros.
+func significantDigits(s string) int {
+	digits := strings.TrimLeft(strings.NewReplacer("-", "", "+", "", ".", "").Replace(s), "0")
+	return len(digits)
+}
+
+// sanitizeSheetName replaces the characters Excel does not allow in sheet names.
+func sanitizeSheetName(name string) string {
+	name = strings.NewReplacer(
+		"[", "_", "]", "_", ":", "_", "*", "_", "?", "_", "/", "_", "\\", "_",
+	).Replace(name)
+	name = strings.Trim(name, "'")
+	if name == "" {
+		name = "_"
+	}
+	return name
+}
+
+func truncateRunes(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n])
+}